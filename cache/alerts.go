@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// AlertFilter limits the alerts returned by SearchAlerts. Empty fields are not filtered on
+type AlertFilter struct {
+	State    string
+	Event    string
+	Severity string
+	// ByPoint only return alerts whose area contains Lat and Lon. Alerts that only list their zones need
+	// the zones' outlines resolved before they are inserted
+	ByPoint bool
+	Lat     float64
+	Lon     float64
+}
+
+// alertDocument the alert as stored in the index
+type alertDocument struct {
+	Alert  weather.Alert `json:"alert"`
+	States []string      `json:"states"`
+}
+
+// InsertAlerts into the Elastic index
+func (s *ElasticStore) InsertAlerts(ctx context.Context, index string, alerts []weather.Alert) (BulkResult, error) {
+
+	documents := make([]bulkDocument, 0, len(alerts))
+
+	for _, alert := range alerts {
+
+		b, err := json.Marshal(alertDocument{Alert: alert, States: alert.States()})
+
+		if err != nil {
+			return BulkResult{}, fmt.Errorf("Unable to marshall alert %s: %s", alert.ID, err)
+		}
+
+		theID := alert.Props.AlertID
+
+		if theID == "" {
+			theID = documentID(alert.ID)
+		}
+
+		documents = append(documents, bulkDocument{ID: theID, Body: b})
+	}
+
+	return s.bulkIndex(ctx, index, documents)
+}
+
+// matches the alert is unexpired at now and passes the filter
+func (filter AlertFilter) matches(alert weather.Alert, now time.Time) bool {
+
+	if !alert.Props.Expires.After(now) {
+		return false
+	}
+
+	if filter.State != "" {
+		found := false
+
+		for _, state := range alert.States() {
+			if strings.EqualFold(state, filter.State) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if filter.Event != "" && !strings.Contains(strings.ToLower(alert.Props.Event), strings.ToLower(filter.Event)) {
+		return false
+	}
+
+	if filter.Severity != "" && !strings.EqualFold(alert.Props.Severity, filter.Severity) {
+		return false
+	}
+
+	return !filter.ByPoint || alert.Contains(filter.Lat, filter.Lon)
+}
+
+// alertQuery the Elastic query for the filter. Expired alerts are never returned
+func alertQuery(filter AlertFilter) map[string]interface{} {
+
+	filters := []interface{}{
+		map[string]interface{}{
+			"range": map[string]interface{}{
+				"alert.properties.expires": map[string]interface{}{"gte": "now"},
+			},
+		},
+	}
+
+	if filter.State != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"states": strings.ToUpper(filter.State)},
+		})
+	}
+
+	if filter.Event != "" {
+		filters = append(filters, map[string]interface{}{
+			"match_phrase": map[string]interface{}{"alert.properties.event": filter.Event},
+		})
+	}
+
+	if filter.Severity != "" {
+		filters = append(filters, map[string]interface{}{
+			"match": map[string]interface{}{"alert.properties.severity": filter.Severity},
+		})
+	}
+
+	if filter.ByPoint {
+		filters = append(filters, map[string]interface{}{
+			"geo_shape": map[string]interface{}{
+				"alert.geometry": map[string]interface{}{
+					"shape": map[string]interface{}{
+						"type":        "point",
+						"coordinates": []float64{filter.Lon, filter.Lat},
+					},
+					"relation": "intersects",
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+	}
+}
+
+// SearchAlerts the unexpired alerts in the Elastic index matching the filter
+func (s *ElasticStore) SearchAlerts(ctx context.Context, index string, filter AlertFilter) ([]weather.Alert, error) {
+
+	var buf bytes.Buffer
+
+	err := json.NewEncoder(&buf).Encode(alertQuery(filter))
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(&buf),
+		s.client.Search.WithSize(10000),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("[%s] searching alerts failed", res.Status())
+	}
+
+	var r struct {
+		Hits struct {
+			Hits []struct {
+				Source alertDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	alerts := make([]weather.Alert, 0, len(r.Hits.Hits))
+
+	for _, hit := range r.Hits.Hits {
+		alerts = append(alerts, hit.Source.Alert)
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultBoltPath the database file, on the volume the Dockerfile declares
+const DefaultBoltPath = "/data/go-weather.db"
+
+// BoltStore the Store kept in a BoltDB file. Each index is a bucket named for the kind of
+// document and the index, e.g. features/features
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore open or create the database file
+func NewBoltStore(path string) (*BoltStore, error) {
+
+	if path == "" {
+		path = DefaultBoltPath
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open %s: %s", path, err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Close the database file
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func stationsBucket(index string) []byte {
+	return []byte("stations/" + index)
+}
+
+func featuresBucket(index string) []byte {
+	return []byte("features/" + index)
+}
+
+func alertsBucket(index string) []byte {
+	return []byte("alerts/" + index)
+}
+
+func observationsBucket(index string) []byte {
+	return []byte("observations/" + index)
+}
+
+// put marshal the values into the bucket, creating it if needed. The values are written in one
+// transaction so either all or none of them are indexed
+func (s *BoltStore) put(bucket []byte, values map[string]interface{}) (BulkResult, error) {
+
+	start := time.Now()
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucket)
+
+		if err != nil {
+			return err
+		}
+
+		for key, value := range values {
+			v, err := json.Marshal(value)
+
+			if err != nil {
+				return fmt.Errorf("Unable to marshall %s: %s", key, err)
+			}
+
+			if err = b.Put([]byte(key), v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return BulkResult{Failed: uint64(len(values)), Duration: time.Since(start)}, err
+	}
+
+	return BulkResult{Indexed: uint64(len(values)), Duration: time.Since(start)}, nil
+}
+
+// each call fn with every key and value in the bucket, in key order, until the context is done
+func (s *BoltStore) each(ctx context.Context, bucket []byte, fn func(k []byte, v []byte) error) error {
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k []byte, v []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			return fn(k, v)
+		})
+	})
+}
+
+// has the key is in the bucket
+func (s *BoltStore) has(bucket []byte, key string) (bool, error) {
+
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+
+		found = b != nil && b.Get([]byte(key)) != nil
+
+		return nil
+	})
+
+	return found, err
+}
+
+// IndexCount the number of documents in the index
+func (s *BoltStore) IndexCount(ctx context.Context, index string) (int64, error) {
+
+	var count int64
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{stationsBucket(index), featuresBucket(index), alertsBucket(index), observationsBucket(index), summaryBucket(index)} {
+			if b := tx.Bucket(bucket); b != nil {
+				count += int64(b.Stats().KeyN)
+			}
+		}
+
+		return nil
+	})
+
+	return count, err
+}
+
+// Contains the station id is in the stations index
+func (s *BoltStore) Contains(ctx context.Context, index string, stationID string) (bool, error) {
+	return s.has(stationsBucket(index), stationID)
+}
+
+// ContainsFeature the station id is in the features index
+func (s *BoltStore) ContainsFeature(ctx context.Context, index string, ID string) (bool, error) {
+	return s.has(featuresBucket(index), ID)
+}
+
+// InsertStationList insert the station URLs into the index
+func (s *BoltStore) InsertStationList(ctx context.Context, index string, stations []string) (BulkResult, error) {
+
+	values := make(map[string]interface{}, len(stations))
+
+	for _, station := range stations {
+		values[documentID(station)] = station
+	}
+
+	return s.put(stationsBucket(index), values)
+}
+
+// InsertFeatures insert the features into the index
+func (s *BoltStore) InsertFeatures(ctx context.Context, index string, features []weather.Feature) (BulkResult, error) {
+
+	values := make(map[string]interface{}, len(features))
+	cachedAt := time.Now().UTC()
+
+	for _, feature := range features {
+		values[documentID(feature.ID)] = CachedFeature{Feature: feature, CachedAt: cachedAt}
+	}
+
+	return s.put(featuresBucket(index), values)
+}
+
+// GetFeature the feature for the station id. False when it is not in the index
+func (s *BoltStore) GetFeature(ctx context.Context, index string, ID string) (CachedFeature, bool, error) {
+
+	var cached CachedFeature
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(featuresBucket(index))
+
+		if b == nil {
+			return nil
+		}
+
+		v := b.Get([]byte(ID))
+
+		if v == nil {
+			return nil
+		}
+
+		found = true
+
+		return json.Unmarshal(v, &cached)
+	})
+
+	return cached, found, err
+}
+
+// NearestStations the features within radiusKm of the point, closest first
+func (s *BoltStore) NearestStations(ctx context.Context, index string, lat float64, lon float64, radiusKm float64, limit int) ([]StationDistance, error) {
+
+	features := make([]weather.Feature, 0)
+
+	err := s.each(ctx, featuresBucket(index), func(k []byte, v []byte) error {
+		var cached CachedFeature
+
+		if err := json.Unmarshal(v, &cached); err != nil {
+			return err
+		}
+
+		features = append(features, cached.Feature)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nearest(features, lat, lon, radiusKm, limit), nil
+}
+
+// InsertAlerts insert the alerts into the index
+func (s *BoltStore) InsertAlerts(ctx context.Context, index string, alerts []weather.Alert) (BulkResult, error) {
+
+	values := make(map[string]interface{}, len(alerts))
+
+	for _, alert := range alerts {
+		values[alert.ID] = alert
+	}
+
+	return s.put(alertsBucket(index), values)
+}
+
+// SearchAlerts the unexpired alerts in the index matching the filter
+func (s *BoltStore) SearchAlerts(ctx context.Context, index string, filter AlertFilter) ([]weather.Alert, error) {
+
+	now := time.Now()
+	alerts := make([]weather.Alert, 0)
+
+	err := s.each(ctx, alertsBucket(index), func(k []byte, v []byte) error {
+		var alert weather.Alert
+
+		if err := json.Unmarshal(v, &alert); err != nil {
+			return err
+		}
+
+		if filter.matches(alert, now) {
+			alerts = append(alerts, alert)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Props.Sent.After(alerts[j].Props.Sent)
+	})
+
+	return alerts, nil
+}
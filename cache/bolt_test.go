@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+func TestBoltStore(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-weather")
+
+	if err != nil {
+		t.Fatalf("Failed creating temp dir. %+v\n", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "go-weather.db")
+
+	store, err := NewBoltStore(path)
+
+	if err != nil {
+		t.Fatalf("Failed opening the bolt store. %+v\n", err)
+	}
+
+	result, err := store.InsertStationList(context.Background(), "stations", []string{
+		"https://api.weather.gov/stations/KSFO",
+		"https://api.weather.gov/stations/KCRG",
+	})
+
+	if err != nil || result.Indexed != 2 {
+		t.Errorf("Expected 2 stations indexed. %+v %+v\n", result, err)
+	}
+
+	store.InsertFeatures(context.Background(), "features", []weather.Feature{
+		{ID: "https://api.weather.gov/stations/KEFK"},
+	})
+
+	store.Close()
+
+	// the data survives reopening the file
+	store, err = NewBoltStore(path)
+
+	if err != nil {
+		t.Fatalf("Failed reopening the bolt store. %+v\n", err)
+	}
+
+	defer store.Close()
+
+	found, err := store.Contains(context.Background(), "stations", "KCRG")
+	missing, _ := store.Contains(context.Background(), "stations", "edwinfailed")
+
+	if err != nil || !found || missing {
+		t.Errorf("Contains not as expected. %+v\n", err)
+	}
+
+	found, err = store.ContainsFeature(context.Background(), "features", "KEFK")
+	missing, _ = store.ContainsFeature(context.Background(), "features", "edwinfailed")
+
+	if err != nil || !found || missing {
+		t.Errorf("ContainsFeature not as expected. %+v\n", err)
+	}
+
+	count, err := store.IndexCount(context.Background(), "stations")
+
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 stations. %d %+v\n", count, err)
+	}
+
+	stations, err := ListStations(store, "stations")
+
+	if err != nil || len(stations) != 2 || stations[0] != "https://api.weather.gov/stations/KCRG" {
+		t.Errorf("Station list not as expected. %v %+v\n", stations, err)
+	}
+
+	it := NewStationIterator(store, "stations", 1)
+	count = 0
+
+	for it.Next() {
+		count++
+	}
+
+	if it.Err() != nil || count != 2 {
+		t.Errorf("Expected to iterate 2 stations a page at a time. %d %+v\n", count, it.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err = store.SearchStations(ctx, "features", StationFilter{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled context to stop the search. %+v\n", err)
+	}
+}
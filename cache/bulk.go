@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+)
+
+// maxBulkFailures the most per document failures kept in a BulkResult
+const maxBulkFailures = 100
+
+// BulkFailure a document that was not indexed
+type BulkFailure struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// BulkResult what happened to the documents of a bulk insert
+type BulkResult struct {
+	Indexed  uint64        `json:"indexed"`
+	Failed   uint64        `json:"failed"`
+	Failures []BulkFailure `json:"failures,omitempty"` // at most the first 100
+	Duration time.Duration `json:"-"`
+}
+
+// MarshalJSON write the duration in a readable form
+func (r BulkResult) MarshalJSON() ([]byte, error) {
+	type result BulkResult
+
+	return json.Marshal(struct {
+		result
+		Duration string `json:"duration"`
+	}{result(r), r.Duration.String()})
+}
+
+// Add the other result to this one, as when inserting a page at a time
+func (r *BulkResult) Add(other BulkResult) {
+	r.Indexed += other.Indexed
+	r.Failed += other.Failed
+	r.Duration += other.Duration
+
+	for _, failure := range other.Failures {
+		if len(r.Failures) == maxBulkFailures {
+			break
+		}
+
+		r.Failures = append(r.Failures, failure)
+	}
+}
+
+// BulkError some of the documents were not indexed
+type BulkError struct {
+	Result BulkResult
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("Indexed [%s] documents with [%s] errors",
+		humanize.Comma(int64(e.Result.Indexed)),
+		humanize.Comma(int64(e.Result.Failed)))
+}
+
+// bulkDocument a document to index with its id
+type bulkDocument struct {
+	ID   string
+	Body []byte
+	// Index where the document goes, when not the index of the bulk insert
+	Index string
+}
+
+// bulkIndex index the documents. The error is a *BulkError when only some of the documents failed
+func (s *ElasticStore) bulkIndex(ctx context.Context, index string, documents []bulkDocument) (BulkResult, error) {
+
+	start := time.Now()
+
+	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:        s.client,
+		Index:         index,
+		NumWorkers:    4,
+		FlushBytes:    5e+6,
+		FlushInterval: 30 * time.Second,
+	})
+
+	if err != nil {
+		return BulkResult{}, fmt.Errorf("Unable to create bulk indexer: %s", err)
+	}
+
+	var mu sync.Mutex
+	var result BulkResult
+
+	onFailure := func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+		failure := BulkFailure{ID: item.DocumentID, Type: res.Error.Type, Reason: res.Error.Reason}
+
+		if err != nil {
+			failure.Type = "error"
+			failure.Reason = err.Error()
+		}
+
+		log.Printf("ERROR: %s %s: %s", failure.ID, failure.Type, failure.Reason)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(result.Failures) < maxBulkFailures {
+			result.Failures = append(result.Failures, failure)
+		}
+	}
+
+	for _, document := range documents {
+
+		err = bi.Add(
+			ctx,
+			esutil.BulkIndexerItem{
+				Action:     "index",
+				Index:      document.Index,
+				DocumentID: document.ID,
+				Body:       bytes.NewReader(document.Body),
+				OnFailure:  onFailure,
+			},
+		)
+
+		if err != nil {
+			bi.Close(ctx)
+			return BulkResult{}, fmt.Errorf("Error bulk indexing: %s", err)
+		}
+	}
+
+	if err = bi.Close(ctx); err != nil {
+		return BulkResult{}, err
+	}
+
+	biStats := bi.Stats()
+
+	result.Indexed = biStats.NumFlushed
+	result.Failed = biStats.NumFailed
+	result.Duration = time.Since(start)
+
+	// Report the results: number of indexed docs, number of errors, duration
+	//
+	log.Println(strings.Repeat("▔", 65))
+
+	if result.Failed > 0 {
+		err := &BulkError{Result: result}
+		log.Printf("%s in %s", err, result.Duration)
+		return result, err
+	}
+
+	log.Printf(
+		"Sucessfuly indexed [%s] documents in %s",
+		humanize.Comma(int64(result.Indexed)),
+		result.Duration,
+	)
+
+	return result, nil
+}
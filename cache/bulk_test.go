@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBulkResult(t *testing.T) {
+
+	var total BulkResult
+
+	total.Add(BulkResult{Indexed: 10, Duration: time.Second})
+	total.Add(BulkResult{Indexed: 5, Failed: 1, Failures: []BulkFailure{{ID: "KSFO", Type: "mapper_parsing_exception"}}, Duration: time.Second})
+
+	if total.Indexed != 15 || total.Failed != 1 || len(total.Failures) != 1 || total.Duration != 2*time.Second {
+		t.Errorf("Added result not as expected. %+v\n", total)
+	}
+
+	b, err := json.Marshal(total)
+
+	if err != nil || !strings.Contains(string(b), `"duration":"2s"`) || !strings.Contains(string(b), `"indexed":15`) {
+		t.Errorf("Result JSON not as expected. %s %+v\n", b, err)
+	}
+
+	var err2 error = &BulkError{Result: total}
+	var bulkErr *BulkError
+
+	if !errors.As(err2, &bulkErr) || bulkErr.Result.Failed != 1 {
+		t.Errorf("Expected a BulkError. %+v\n", err2)
+	}
+
+	if err2.Error() != "Indexed [15] documents with [1] errors" {
+		t.Errorf("Error message not as expected. %s\n", err2)
+	}
+}
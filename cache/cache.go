@@ -1,21 +1,13 @@
 package cache
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/EdSwArchitect/go-weather/weather"
-	"github.com/cenkalti/backoff/v4"
-	"github.com/dustin/go-humanize"
-	"github.com/elastic/go-elasticsearch/v8"
-	"github.com/elastic/go-elasticsearch/v8/esutil"
 )
 
 // Shards structure
@@ -32,539 +24,341 @@ type CountResult struct {
 	ShardInfo Shards `json:"_shards"`
 }
 
-var es *elasticsearch.Client
-var esHost string
-
-// Initialize connection to ElasticSearch
-func Initialize(host string) {
-
-	esHost = host
-
-	retryBackoff := backoff.NewExponentialBackOff()
-
-	var err error
-	var theAddress string
-
-	if !strings.HasPrefix(host, "http://") {
-		theAddress = fmt.Sprintf("http://%s", host)
-	} else {
-		theAddress = host
-	}
-
-	es, err = elasticsearch.NewClient(elasticsearch.Config{
-		Addresses: []string{
-			theAddress,
-		},
-
-		RetryOnStatus: []int{502, 503, 504, 429},
-		// Configure the backoff function
-		//
-		RetryBackoff: func(i int) time.Duration {
-			if i == 1 {
-				retryBackoff.Reset()
-			}
-			return retryBackoff.NextBackOff()
-		},
-
-		// Retry up to 5 attempts
-		//
-		MaxRetries: 5,
-	})
-
-	if err != nil {
-		log.Fatalf("Failed getting connection to elastic: %+v", err)
-	}
-
-	res, err := es.Info()
-	if err != nil {
-		log.Fatalf("Error getting response: %s", err)
-	}
-
-	log.Println(res)
-
+// CachedFeature the feature as stored, with when it was fetched from the weather service
+type CachedFeature struct {
+	Feature  weather.Feature `json:"feature"`
+	CachedAt time.Time       `json:"cachedAt"`
 }
 
-// IndexCount get the index document count
-func IndexCount(index string) (int64, error) {
+// Store where the stations, features and alerts are kept. The context cancels the work in the store
+type Store interface {
+	// IndexCount the number of documents in the index
+	IndexCount(ctx context.Context, index string) (int64, error)
+	// Contains the station id is in the stations index
+	Contains(ctx context.Context, index string, stationID string) (bool, error)
+	// ContainsFeature the station id is in the features index
+	ContainsFeature(ctx context.Context, index string, ID string) (bool, error)
+	// InsertStationList insert the station URLs into the index
+	InsertStationList(ctx context.Context, index string, stations []string) (BulkResult, error)
+	// InsertFeatures insert the features into the index
+	InsertFeatures(ctx context.Context, index string, features []weather.Feature) (BulkResult, error)
+	// GetFeature the feature for the station id. False when it is not in the index
+	GetFeature(ctx context.Context, index string, ID string) (CachedFeature, bool, error)
+	// NearestStations the features within radiusKm of the point, closest first
+	NearestStations(ctx context.Context, index string, lat float64, lon float64, radiusKm float64, limit int) ([]StationDistance, error)
+	// SearchStations a page of the features in the index passing the filter
+	SearchStations(ctx context.Context, index string, filter StationFilter) (FeaturePage, error)
+	// GetStationPage a page of at most size station URLs in the index, after the cursor
+	GetStationPage(ctx context.Context, index string, cursor string, size int) (StationPage, error)
+	// InsertAlerts insert the alerts into the index
+	InsertAlerts(ctx context.Context, index string, alerts []weather.Alert) (BulkResult, error)
+	// SearchAlerts the unexpired alerts in the index matching the filter
+	SearchAlerts(ctx context.Context, index string, filter AlertFilter) ([]weather.Alert, error)
+	// InsertObservations insert the observations into the index, replacing any with the same station and time
+	InsertObservations(ctx context.Context, index string, observations []weather.Observation) (BulkResult, error)
+	// StationObservations the observations of the station in the index from, inclusive, to, exclusive, oldest first
+	StationObservations(ctx context.Context, index string, stationID string, from time.Time, to time.Time) ([]weather.Observation, error)
+	// InsertDailySummaries insert the summaries into the index, replacing any with the same station and date
+	InsertDailySummaries(ctx context.Context, index string, summaries []DailySummary) (BulkResult, error)
+	// DailySummaries the summaries of the station in the index from and to the dates, inclusive, by date
+	DailySummaries(ctx context.Context, index string, stationID string, from string, to string) ([]DailySummary, error)
+	// IndexStats the document count and size of each of the indices
+	IndexStats(ctx context.Context, indices Indices) ([]IndexStat, error)
+	// ApplyRetention delete the observations, alerts and daily summaries older than the retention allows at now
+	ApplyRetention(ctx context.Context, indices Indices, retention Retention, now time.Time) (RetentionResult, error)
+}
 
-	resp, err := http.Get(fmt.Sprintf("http://%s/%s/_count", esHost, index))
+var _ Store = (*ElasticStore)(nil)
+var _ Store = (*MemoryStore)(nil)
+var _ Store = (*BoltStore)(nil)
 
-	if err != nil {
-		return 0, err
-	}
+// The kinds of Store
+const (
+	StoreElastic = "elastic"
+	StoreMemory  = "memory"
+	StoreBolt    = "bolt"
+)
 
-	var countResult CountResult
+// StoreConfig which store to create and where it keeps its data
+type StoreConfig struct {
+	// Kind elastic, memory or bolt. Empty is elastic
+	Kind string
+	// Host the ElasticSearch host and port
+	Host string
+	// Path the BoltDB database file
+	Path string
+	// Indices the index names the ElasticSearch templates are installed for
+	Indices Indices
+}
 
-	defer resp.Body.Close()
+// NewStore create the configured kind of store
+func NewStore(config StoreConfig) (Store, error) {
 
-	err = json.NewDecoder(resp.Body).Decode(&countResult)
+	switch config.Kind {
+	case StoreElastic, "":
+		store, err := NewElasticStore(config.Host)
 
-	if err != nil {
-		return 0, err
-	}
+		if err != nil {
+			return nil, err
+		}
 
-	return countResult.Count, nil
-}
+		indices := config.Indices
 
-// Contains - the station id is contained in the cache
-func Contains(stationID string) bool {
-
-	var buf bytes.Buffer
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"match": map[string]interface{}{
-				"_id": stationID,
-			},
-		},
-	}
+		if indices == (Indices{}) {
+			indices = DefaultIndices
+		}
 
-	err := json.NewEncoder(&buf).Encode(query)
+		if err = store.InstallTemplates(context.Background(), indices); err != nil {
+			return nil, fmt.Errorf("Unable to install index templates: %s", err)
+		}
 
-	if err != nil {
-		log.Fatalf("Error encoding query: %s", err)
-	}
+		if err = store.CheckMappings(context.Background(), indices); err != nil {
+			return nil, err
+		}
 
-	// Perform the search request.
-	res, err := es.Count(
-		es.Count.WithContext(context.Background()),
-		es.Count.WithIndex("stations"),
-		es.Count.WithBody(&buf),
-	)
-	if err != nil {
-		log.Fatalf("Error getting response: %s", err)
+		return store, nil
+	case StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreBolt:
+		return NewBoltStore(config.Path)
+	default:
+		return nil, fmt.Errorf("Unknown store: %s", config.Kind)
 	}
-	defer res.Body.Close()
-
-	var e CountResult
-
-	err = json.NewDecoder(res.Body).Decode(&e)
+}
 
-	if err != nil {
-		log.Fatalf("Error decoding: %s", err)
-	}
+// documentID the station id at the end of the station URL
+func documentID(uri string) string {
+	return uri[strings.LastIndex(uri, "/")+1:]
+}
 
-	log.Printf("The body: %+v", e)
+// LookupFeature read through the features index. The weather service is called when the feature
+// is missing or was cached longer than the ttl ago, and the result is written back. A ttl of zero
+// or less never expires the cached feature
+func LookupFeature(store Store, index string, stationID string, ttl time.Duration,
+	fetch func(stationID string) (weather.Feature, error)) (weather.Feature, error) {
 
-	return e.Count == 1
+	return LookupFeatureContext(context.Background(), store, index, stationID, ttl,
+		func(ctx context.Context, stationID string) (weather.Feature, error) {
+			return fetch(stationID)
+		})
 }
 
-// ContainsFeature - the station id is contained in the cache
-func ContainsFeature(ID string) bool {
-
-	var buf bytes.Buffer
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"match": map[string]interface{}{
-				"_id": ID,
-			},
-		},
-	}
+// LookupFeatureContext LookupFeature with the context passed to the store and the weather service
+func LookupFeatureContext(ctx context.Context, store Store, index string, stationID string, ttl time.Duration,
+	fetch func(ctx context.Context, stationID string) (weather.Feature, error)) (weather.Feature, error) {
 
-	err := json.NewEncoder(&buf).Encode(query)
+	cached, found, err := store.GetFeature(ctx, index, stationID)
 
 	if err != nil {
-		log.Fatalf("Error encoding query: %s", err)
+		log.Printf("Reading feature %s from the cache failed, calling the weather service: %s", stationID, err)
 	}
 
-	// Perform the search request.
-	res, err := es.Count(
-		es.Count.WithContext(context.Background()),
-		es.Count.WithIndex("features"),
-		es.Count.WithBody(&buf),
-	)
-	if err != nil {
-		log.Fatalf("Error getting response: %s", err)
+	if found && (ttl <= 0 || time.Since(cached.CachedAt) < ttl) {
+		return cached.Feature, nil
 	}
-	defer res.Body.Close()
 
-	var e CountResult
-
-	err = json.NewDecoder(res.Body).Decode(&e)
+	feature, err := fetch(ctx, stationID)
 
 	if err != nil {
-		log.Fatalf("Error decoding: %s", err)
+		return weather.Feature{}, err
 	}
 
-	return e.Count == 1
-}
-
-// InsertStations inserts the stations into the Elastic index
-func InsertStations(index string, stations weather.Stations) {
-
-	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Client:        es,
-		Index:         index,
-		NumWorkers:    4,
-		FlushBytes:    5e+6,
-		FlushInterval: 30 * time.Second,
-	})
-
-	if err != nil {
-		log.Fatalf("Unable to create bulk indexer: %s", err)
-		return
+	if _, err = store.InsertFeatures(ctx, index, []weather.Feature{feature}); err != nil {
+		log.Printf("Writing feature %s to the cache failed: %s", stationID, err)
 	}
 
-	var countSuccessful uint64
-
-	for _, station := range stations.ObservationStations {
+	return feature, nil
+}
 
-		var b strings.Builder
-		b.WriteString(`{"station" : "`)
-		b.WriteString(station)
-		b.WriteString(`"}`)
+// elastic the store used by the package level functions
+var elastic *ElasticStore
 
-		idx := strings.LastIndex(station, "/")
+// Initialize connection to ElasticSearch
+func Initialize(host string) error {
 
-		stationRune := []rune(station)
-		theID := string(stationRune[idx+1:])
+	var err error
 
-		err = bi.Add(
-			context.Background(),
-			esutil.BulkIndexerItem{
-				Action:     "index",
-				DocumentID: theID,
-				Body:       strings.NewReader(b.String()),
+	elastic, err = NewElasticStore(host)
 
-				// OnSuccess is called for each successful operation
-				OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
-					atomic.AddUint64(&countSuccessful, 1)
-				},
+	return err
+}
 
-				// OnFailure is called for each failed operation
-				OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
-					if err != nil {
-						log.Printf("ERROR: %s", err)
-					} else {
-						log.Printf("ERROR: %s: %s", res.Error.Type, res.Error.Reason)
-					}
-				},
-			},
-		)
+// IndexCount get the index document count
+func IndexCount(index string) (int64, error) {
+	return elastic.IndexCount(context.Background(), index)
+}
 
-		if err != nil {
-			log.Printf("Error bulk indexing: %s\n", err)
-			return
-		}
-	} // for _, station := range stations.ObservationStations {
+// IndexCountContext get the index document count
+func IndexCountContext(ctx context.Context, index string) (int64, error) {
+	return elastic.IndexCount(ctx, index)
+}
 
-	if err = bi.Close(context.Background()); err != nil {
+// Contains - the station id is contained in the cache
+func Contains(stationID string) (bool, error) {
+	return elastic.Contains(context.Background(), DefaultIndices.Stations, stationID)
+}
 
-		log.Fatalf("Some fatal error: %s", err)
-	}
+// ContainsContext - the station id is contained in the cache
+func ContainsContext(ctx context.Context, stationID string) (bool, error) {
+	return elastic.Contains(ctx, DefaultIndices.Stations, stationID)
+}
 
-	biStats := bi.Stats()
-
-	// Report the results: number of indexed docs, number of errors, duration, indexing rate
-	//
-	log.Println(strings.Repeat("▔", 65))
-
-	if biStats.NumFailed > 0 {
-		log.Fatalf(
-			"Indexed [%s] documents with [%s] errors",
-			humanize.Comma(int64(biStats.NumFlushed)),
-			humanize.Comma(int64(biStats.NumFailed)),
-		)
-	} else {
-		log.Printf(
-			"Sucessfuly indexed [%s] documents",
-			humanize.Comma(int64(biStats.NumFlushed)),
-		)
-	}
+// ContainsFeature - the station id is contained in the cache
+func ContainsFeature(ID string) (bool, error) {
+	return elastic.ContainsFeature(context.Background(), DefaultIndices.Features, ID)
+}
 
+// ContainsFeatureContext - the station id is contained in the cache
+func ContainsFeatureContext(ctx context.Context, ID string) (bool, error) {
+	return elastic.ContainsFeature(ctx, DefaultIndices.Features, ID)
 }
 
 // InsertStations inserts the stations into the Elastic index
-func InsertStationList(index string, stations []string) {
-
-	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Client:        es,
-		Index:         index,
-		NumWorkers:    4,
-		FlushBytes:    5e+6,
-		FlushInterval: 30 * time.Second,
-	})
-
-	if err != nil {
-		log.Fatalf("Unable to create bulk indexer: %s", err)
-		return
-	}
-
-	var countSuccessful uint64
-
-	for _, station := range stations {
-
-		var b strings.Builder
-		b.WriteString(`{"station" : "`)
-		b.WriteString(station)
-		b.WriteString(`"}`)
-
-		idx := strings.LastIndex(station, "/")
-
-		stationRune := []rune(station)
-		theID := string(stationRune[idx+1:])
-
-		err = bi.Add(
-			context.Background(),
-			esutil.BulkIndexerItem{
-				Action:     "index",
-				DocumentID: theID,
-				Body:       strings.NewReader(b.String()),
-
-				// OnSuccess is called for each successful operation
-				OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
-					atomic.AddUint64(&countSuccessful, 1)
-				},
-
-				// OnFailure is called for each failed operation
-				OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
-					if err != nil {
-						log.Printf("ERROR: %s", err)
-					} else {
-						log.Printf("ERROR: %s: %s", res.Error.Type, res.Error.Reason)
-					}
-				},
-			},
-		)
-
-		if err != nil {
-			log.Printf("Error bulk indexing: %s\n", err)
-			return
-		}
-	} // for _, station := range stations.ObservationStations {
-
-	if err = bi.Close(context.Background()); err != nil {
+func InsertStations(index string, stations weather.Stations) (BulkResult, error) {
+	return elastic.InsertStationList(context.Background(), index, stations.ObservationStations)
+}
 
-		log.Fatalf("Some fatal error: %s", err)
-	}
+// InsertStationsContext inserts the stations into the Elastic index
+func InsertStationsContext(ctx context.Context, index string, stations weather.Stations) (BulkResult, error) {
+	return elastic.InsertStationList(ctx, index, stations.ObservationStations)
+}
 
-	biStats := bi.Stats()
-
-	// Report the results: number of indexed docs, number of errors, duration, indexing rate
-	//
-	log.Println(strings.Repeat("▔", 65))
-
-	if biStats.NumFailed > 0 {
-		log.Fatalf(
-			"Indexed [%s] documents with [%s] errors",
-			humanize.Comma(int64(biStats.NumFlushed)),
-			humanize.Comma(int64(biStats.NumFailed)),
-		)
-	} else {
-		log.Printf(
-			"Sucessfuly indexed [%s] documents",
-			humanize.Comma(int64(biStats.NumFlushed)),
-		)
-	}
+// InsertStationList inserts the stations into the Elastic index
+func InsertStationList(index string, stations []string) (BulkResult, error) {
+	return elastic.InsertStationList(context.Background(), index, stations)
+}
 
+// InsertStationListContext inserts the stations into the Elastic index
+func InsertStationListContext(ctx context.Context, index string, stations []string) (BulkResult, error) {
+	return elastic.InsertStationList(ctx, index, stations)
 }
 
-// GetStationList inserts the stations into the Elastic index
+// GetStationList every station in the Elastic index
 func GetStationList(index string) ([]string, error) {
-	// func GetStationList(index string) ([]map[string]string, error) {
-
-	var buf bytes.Buffer
-
-	// query := `{
-	// 	"query": {
-	// 		"match_all": {}
-	// 	}
-	// }`
-
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"match_all": map[string]interface{}{},
-		},
-	}
-
-	err := json.NewEncoder(&buf).Encode(query)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Perform the search request.
-	res, err := es.Search(
-		es.Search.WithContext(context.Background()),
-		es.Search.WithIndex(index),
-		es.Search.WithBody(&buf),
-		es.Search.WithTrackTotalHits(true),
-		es.Search.WithSize(10000),
-		es.Search.WithPretty(),
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if res.IsError() {
-		var e map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
-			return nil, err
-		} else {
-			// Print the response status and error information.
-			return nil, fmt.Errorf("[%s] %s: %s", res.Status(), e["error"].(map[string]interface{})["type"], e["error"].(map[string]interface{})["reason"])
-
-		}
-	}
-
-	var r map[string]interface{}
-
-	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
-		return nil, err
-	}
-
-	// fmt.Printf("hits is: %v\n", r["hits"])
-
-	// int(r["hits"])
-
-	stations := make([]string, 1)
-	// stations := make([]map[string]string, 1)
-
-	//   // Print the response status, number of results, and request duration.
-	//   log.Printf(
-	// 	"[%s] %d hits; took: %dms",
-	// 	res.Status(),
-	// 	int(r["hits"].(map[string]interface{})["total"].(map[string]interface{})["value"].(float64)),
-	// 	int(r["took"].(float64)),
-	//   )
-
-	// Print the ID and document source for each hit.
-	for _, hit := range r["hits"].(map[string]interface{})["hits"].([]interface{}) {
-		// stations[i] = fmt.Sprintf("%s", hit.(map[string]interface{})["_source"])
-
-		// var m map[string]interface{}
-
-		var m interface{}
-
-		m = hit.(map[string]interface{})["_source"]
-
-		switch dd := m.(type) {
-
-		case map[string]interface{}:
-			// fmt.Printf("map[string]interface{}: %s\n", dd)
+	return ListStationsContext(context.Background(), elastic, index)
+}
 
-			for _, v := range dd {
-				// fmt.Printf("\t\tKey: %s. Value: %s  -- ", k, v)
+// GetStationListContext every station in the Elastic index
+func GetStationListContext(ctx context.Context, index string) ([]string, error) {
+	return ListStationsContext(ctx, elastic, index)
+}
 
-				s, ok := v.(string)
+// InsertFeatures into the Elastic index
+func InsertFeatures(index string, features []weather.Feature) (BulkResult, error) {
+	return elastic.InsertFeatures(context.Background(), index, features)
+}
 
-				if ok {
-					// fmt.Println("string")
+// InsertFeaturesContext into the Elastic index
+func InsertFeaturesContext(ctx context.Context, index string, features []weather.Feature) (BulkResult, error) {
+	return elastic.InsertFeatures(ctx, index, features)
+}
 
-					stations = append(stations, s)
+// GetFeature the feature for the station id in the Elastic index
+func GetFeature(index string, ID string) (CachedFeature, bool, error) {
+	return elastic.GetFeature(context.Background(), index, ID)
+}
 
-				} else {
-					_, ok = v.(interface{})
-					if ok {
-						fmt.Println(" and interface{}")
-					}
-				}
+// GetFeatureContext the feature for the station id in the Elastic index
+func GetFeatureContext(ctx context.Context, index string, ID string) (CachedFeature, bool, error) {
+	return elastic.GetFeature(ctx, index, ID)
+}
 
-			}
+// NearestStations the features in the Elastic index within radiusKm of the point, closest first
+func NearestStations(index string, lat float64, lon float64, radiusKm float64, limit int) ([]StationDistance, error) {
+	return elastic.NearestStations(context.Background(), index, lat, lon, radiusKm, limit)
+}
 
-		case map[string]string:
-			// fmt.Printf("map[string]string: %s\n", dd)
-		default:
-			// fmt.Println("Something else")
-		}
+// NearestStationsContext the features in the Elastic index within radiusKm of the point, closest first
+func NearestStationsContext(ctx context.Context, index string, lat float64, lon float64, radiusKm float64, limit int) ([]StationDistance, error) {
+	return elastic.NearestStations(ctx, index, lat, lon, radiusKm, limit)
+}
 
-		// fmt.Printf("*** %v ***: %s\n-----\n", reflect.TypeOf(m), m)
+// SearchStations a page of the features in the Elastic index passing the filter
+func SearchStations(index string, filter StationFilter) (FeaturePage, error) {
+	return elastic.SearchStations(context.Background(), index, filter)
+}
 
-		// stations = append(stations, fmt.Sprintf("%s", hit.(map[string]interface{})["_source"]))
+// SearchStationsContext a page of the features in the Elastic index passing the filter
+func SearchStationsContext(ctx context.Context, index string, filter StationFilter) (FeaturePage, error) {
+	return elastic.SearchStations(ctx, index, filter)
+}
 
-	}
+// InsertAlerts into the Elastic index
+func InsertAlerts(index string, alerts []weather.Alert) (BulkResult, error) {
+	return elastic.InsertAlerts(context.Background(), index, alerts)
+}
 
-	// fmt.Printf("The stations returned: %+v", stations)
+// InsertAlertsContext into the Elastic index
+func InsertAlertsContext(ctx context.Context, index string, alerts []weather.Alert) (BulkResult, error) {
+	return elastic.InsertAlerts(ctx, index, alerts)
+}
 
-	return stations, nil
+// SearchAlerts the unexpired alerts in the Elastic index matching the filter
+func SearchAlerts(index string, filter AlertFilter) ([]weather.Alert, error) {
+	return elastic.SearchAlerts(context.Background(), index, filter)
 }
 
-// InsertFeatures into the Elastic index
-func InsertFeatures(index string, features []weather.Feature) {
+// SearchAlertsContext the unexpired alerts in the Elastic index matching the filter
+func SearchAlertsContext(ctx context.Context, index string, filter AlertFilter) ([]weather.Alert, error) {
+	return elastic.SearchAlerts(ctx, index, filter)
+}
 
-	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Client:        es,
-		Index:         index,
-		NumWorkers:    4,
-		FlushBytes:    5e+6,
-		FlushInterval: 30 * time.Second,
-	})
+// InsertObservations into the Elastic index
+func InsertObservations(index string, observations []weather.Observation) (BulkResult, error) {
+	return elastic.InsertObservations(context.Background(), index, observations)
+}
 
-	if err != nil {
-		log.Fatalf("Unable to create bulk indexer: %s", err)
-	}
+// InsertObservationsContext into the Elastic index
+func InsertObservationsContext(ctx context.Context, index string, observations []weather.Observation) (BulkResult, error) {
+	return elastic.InsertObservations(ctx, index, observations)
+}
 
-	var countSuccessful uint64
+// StationObservations the observations of the station in the Elastic index from, inclusive, to, exclusive
+func StationObservations(index string, stationID string, from time.Time, to time.Time) ([]weather.Observation, error) {
+	return elastic.StationObservations(context.Background(), index, stationID, from, to)
+}
 
-	for _, feature := range features {
+// StationObservationsContext the observations of the station in the Elastic index from, inclusive, to, exclusive
+func StationObservationsContext(ctx context.Context, index string, stationID string, from time.Time, to time.Time) ([]weather.Observation, error) {
+	return elastic.StationObservations(ctx, index, stationID, from, to)
+}
 
-		f, err := json.Marshal(feature)
+// InsertDailySummaries into the Elastic index
+func InsertDailySummaries(index string, summaries []DailySummary) (BulkResult, error) {
+	return elastic.InsertDailySummaries(context.Background(), index, summaries)
+}
 
-		if err != nil {
-			log.Fatalf("Unable to marshall object: %s", err)
-		}
+// InsertDailySummariesContext into the Elastic index
+func InsertDailySummariesContext(ctx context.Context, index string, summaries []DailySummary) (BulkResult, error) {
+	return elastic.InsertDailySummaries(ctx, index, summaries)
+}
 
-		var b strings.Builder
-		b.WriteString(`{"feature" : `)
-		b.WriteString(string(f))
-		b.WriteString(`}`)
-
-		idx := strings.LastIndex(feature.ID, "/")
-
-		stationRune := []rune(feature.ID)
-
-		theID := string(stationRune[idx+1:])
-
-		err = bi.Add(
-			context.Background(),
-			esutil.BulkIndexerItem{
-				Action:     "index",
-				DocumentID: theID,
-				Body:       strings.NewReader(b.String()),
-				// OnSuccess is called for each successful operation
-				OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
-					atomic.AddUint64(&countSuccessful, 1)
-				},
-
-				// OnFailure is called for each failed operation
-				OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
-					if err != nil {
-						log.Printf("ERROR: %s", err)
-					} else {
-						log.Printf("ERROR: %s: %s", res.Error.Type, res.Error.Reason)
-					}
-				},
-			},
-		)
+// DailySummaries the summaries of the station in the Elastic index from and to the dates, inclusive
+func DailySummaries(index string, stationID string, from string, to string) ([]DailySummary, error) {
+	return elastic.DailySummaries(context.Background(), index, stationID, from, to)
+}
 
-		if err != nil {
-			log.Printf("Error bulk indexing: %s\n", err)
-			return
-		}
-	} // for _, station := range stations.ObservationStations {
+// DailySummariesContext the summaries of the station in the Elastic index from and to the dates, inclusive
+func DailySummariesContext(ctx context.Context, index string, stationID string, from string, to string) ([]DailySummary, error) {
+	return elastic.DailySummaries(ctx, index, stationID, from, to)
+}
 
-	if err = bi.Close(context.Background()); err != nil {
+// IndexStats the document count and size of each of the Elastic indices
+func IndexStats(indices Indices) ([]IndexStat, error) {
+	return elastic.IndexStats(context.Background(), indices)
+}
 
-		log.Fatalf("Some fatal error: %s", err)
-	}
+// IndexStatsContext the document count and size of each of the Elastic indices
+func IndexStatsContext(ctx context.Context, indices Indices) ([]IndexStat, error) {
+	return elastic.IndexStats(ctx, indices)
+}
 
-	biStats := bi.Stats()
-
-	// Report the results: number of indexed docs, number of errors, duration, indexing rate
-	//
-	log.Println(strings.Repeat("▔", 65))
-
-	if biStats.NumFailed > 0 {
-		log.Fatalf(
-			"Indexed [%s] documents with [%s] errors",
-			humanize.Comma(int64(biStats.NumFlushed)),
-			humanize.Comma(int64(biStats.NumFailed)),
-		)
-	} else {
-		log.Printf(
-			"Sucessfuly indexed [%s] documents",
-			humanize.Comma(int64(biStats.NumFlushed)),
-		)
-	}
+// ApplyRetention delete the Elastic observations and alerts older than the retention allows
+func ApplyRetention(indices Indices, retention Retention) (RetentionResult, error) {
+	return elastic.ApplyRetention(context.Background(), indices, retention, time.Now())
+}
 
+// ApplyRetentionContext delete the Elastic observations and alerts older than the retention allows
+func ApplyRetentionContext(ctx context.Context, indices Indices, retention Retention) (RetentionResult, error) {
+	return elastic.ApplyRetention(ctx, indices, retention, time.Now())
 }
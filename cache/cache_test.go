@@ -16,7 +16,9 @@ func TestCache(t *testing.T) {
 
 	log.Println("TestCache")
 
-	Initialize("http://localhost:9200")
+	if err := Initialize("http://localhost:9200"); err != nil {
+		t.Fatalf("Unable to connect. %+v\n", err)
+	}
 
 	stations, err := weather.GetObservationStations()
 
@@ -26,7 +28,11 @@ func TestCache(t *testing.T) {
 
 	log.Printf("****** Stations length: %d", len(stations.ObservationStations))
 
-	InsertStations("stations", stations)
+	_, err = InsertStations("stations", stations)
+
+	if err != nil {
+		t.Errorf("Failed inserting stations. %+v\n", err)
+	}
 
 	//
 	// PAJN
@@ -38,15 +44,21 @@ func TestContains(t *testing.T) {
 
 	log.Println("TestContains")
 
-	Initialize("http://localhost:9200")
+	if err := Initialize("http://localhost:9200"); err != nil {
+		t.Fatalf("Unable to connect. %+v\n", err)
+	}
+
+	v, err := Contains("KCRG")
 
-	v := Contains("KCRG")
+	if err != nil {
+		t.Errorf("Failed checking the index. %+v\n", err)
+	}
 
 	if !v {
 		t.Errorf("Should have found 'KCRG' in the index")
 	}
 
-	v = Contains("edwinfailed")
+	v, _ = Contains("edwinfailed")
 
 	if v {
 		t.Errorf("Should NOT have found 'edwinfailed' in the index")
@@ -57,7 +69,9 @@ func TestInsertFeatures(t *testing.T) {
 
 	log.Println("TestInsertFeatures")
 
-	Initialize("http://localhost:9200")
+	if err := Initialize("http://localhost:9200"); err != nil {
+		t.Fatalf("Unable to connect. %+v\n", err)
+	}
 
 	features, err := weather.GetFeatures()
 
@@ -65,22 +79,32 @@ func TestInsertFeatures(t *testing.T) {
 		t.Errorf("Failed Getting features. %+v\n", err)
 	}
 
-	InsertFeatures("features", features)
+	_, err = InsertFeatures("features", features)
+
+	if err != nil {
+		t.Errorf("Failed inserting features. %+v\n", err)
+	}
 }
 
 func TestFeatureContains(t *testing.T) {
 
 	log.Println("TestFeatureContains")
 
-	Initialize("http://localhost:9200")
+	if err := Initialize("http://localhost:9200"); err != nil {
+		t.Fatalf("Unable to connect. %+v\n", err)
+	}
+
+	v, err := ContainsFeature("KEFK")
 
-	v := ContainsFeature("KEFK")
+	if err != nil {
+		t.Errorf("Failed checking the index. %+v\n", err)
+	}
 
 	if !v {
 		t.Errorf("Should have found 'KEFK' in the index")
 	}
 
-	v = ContainsFeature("edwinfailed")
+	v, _ = ContainsFeature("edwinfailed")
 
 	if v {
 		t.Errorf("Should NOT have found 'edwinfailed' in the index")
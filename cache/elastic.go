@@ -0,0 +1,340 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// ElasticStore the Store kept in ElasticSearch
+type ElasticStore struct {
+	client *elasticsearch.Client
+}
+
+// featureDocument the feature as indexed, with the fields the features template maps
+type featureDocument struct {
+	CachedFeature
+	StationID string    `json:"stationId"`
+	State     string    `json:"state,omitempty"`
+	County    string    `json:"county,omitempty"`
+	Location  *GeoPoint `json:"location,omitempty"`
+	Elevation *float64  `json:"elevation,omitempty"` // meters
+}
+
+func newFeatureDocument(feature weather.Feature, cachedAt time.Time) featureDocument {
+
+	doc := featureDocument{
+		CachedFeature: CachedFeature{Feature: feature, CachedAt: cachedAt},
+		StationID:     documentID(feature.ID),
+		State:         feature.State(),
+		County:        feature.CountyZone(),
+	}
+
+	if coordinates := feature.Geo.Coordinates; len(coordinates) >= 2 {
+		doc.Location = &GeoPoint{Lat: coordinates[1], Lon: coordinates[0]}
+	}
+
+	if elevation, ok := feature.Props.TheElevation.In(weather.Meter); ok {
+		doc.Elevation = &elevation
+	}
+
+	return doc
+}
+
+// NewElasticStore connect to ElasticSearch
+func NewElasticStore(host string) (*ElasticStore, error) {
+
+	retryBackoff := backoff.NewExponentialBackOff()
+
+	var theAddress string
+
+	if !strings.HasPrefix(host, "http://") {
+		theAddress = fmt.Sprintf("http://%s", host)
+	} else {
+		theAddress = host
+	}
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{
+			theAddress,
+		},
+
+		RetryOnStatus: []int{502, 503, 504, 429},
+		// Configure the backoff function
+		//
+		RetryBackoff: func(i int) time.Duration {
+			if i == 1 {
+				retryBackoff.Reset()
+			}
+			return retryBackoff.NextBackOff()
+		},
+
+		// Retry up to 5 attempts
+		//
+		MaxRetries: 5,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting connection to elastic: %+v", err)
+	}
+
+	res, err := es.Info()
+	if err != nil {
+		return nil, fmt.Errorf("Error getting response: %s", err)
+	}
+
+	defer res.Body.Close()
+
+	log.Println(res)
+
+	return &ElasticStore{client: es}, nil
+}
+
+// IndexCount get the index document count. A missing index has no documents
+func (s *ElasticStore) IndexCount(ctx context.Context, index string) (int64, error) {
+
+	res, err := s.client.Count(
+		s.client.Count.WithContext(ctx),
+		s.client.Count.WithIndex(index),
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+
+	if res.IsError() {
+		return 0, fmt.Errorf("[%s] counting %s failed", res.Status(), index)
+	}
+
+	var countResult CountResult
+
+	err = json.NewDecoder(res.Body).Decode(&countResult)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return countResult.Count, nil
+}
+
+// containsID the id is in the index
+func (s *ElasticStore) containsID(ctx context.Context, index string, ID string) (bool, error) {
+
+	var buf bytes.Buffer
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{
+				"values": []string{ID},
+			},
+		},
+	}
+
+	err := json.NewEncoder(&buf).Encode(query)
+
+	if err != nil {
+		return false, fmt.Errorf("Error encoding query: %s", err)
+	}
+
+	// Perform the count request.
+	res, err := s.client.Count(
+		s.client.Count.WithContext(ctx),
+		s.client.Count.WithIndex(index),
+		s.client.Count.WithBody(&buf),
+	)
+	if err != nil {
+		return false, fmt.Errorf("Error getting response: %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if res.IsError() {
+		return false, fmt.Errorf("[%s] counting %s in %s failed", res.Status(), ID, index)
+	}
+
+	var e CountResult
+
+	err = json.NewDecoder(res.Body).Decode(&e)
+
+	if err != nil {
+		return false, fmt.Errorf("Error decoding: %s", err)
+	}
+
+	return e.Count == 1, nil
+}
+
+// Contains - the station id is contained in the cache
+func (s *ElasticStore) Contains(ctx context.Context, index string, stationID string) (bool, error) {
+	return s.containsID(ctx, index, stationID)
+}
+
+// ContainsFeature - the station id is contained in the cache
+func (s *ElasticStore) ContainsFeature(ctx context.Context, index string, ID string) (bool, error) {
+	return s.containsID(ctx, index, ID)
+}
+
+// InsertStationList inserts the stations into the Elastic index
+func (s *ElasticStore) InsertStationList(ctx context.Context, index string, stations []string) (BulkResult, error) {
+
+	documents := make([]bulkDocument, 0, len(stations))
+
+	for _, station := range stations {
+
+		b, err := json.Marshal(map[string]string{"station": station})
+
+		if err != nil {
+			return BulkResult{}, fmt.Errorf("Unable to marshall station %s: %s", station, err)
+		}
+
+		documents = append(documents, bulkDocument{ID: documentID(station), Body: b})
+	}
+
+	return s.bulkIndex(ctx, index, documents)
+}
+
+// InsertFeatures into the Elastic index
+func (s *ElasticStore) InsertFeatures(ctx context.Context, index string, features []weather.Feature) (BulkResult, error) {
+
+	documents := make([]bulkDocument, 0, len(features))
+	cachedAt := time.Now().UTC()
+
+	for _, feature := range features {
+
+		b, err := json.Marshal(newFeatureDocument(feature, cachedAt))
+
+		if err != nil {
+			return BulkResult{}, fmt.Errorf("Unable to marshall feature %s: %s", feature.ID, err)
+		}
+
+		documents = append(documents, bulkDocument{ID: documentID(feature.ID), Body: b})
+	}
+
+	return s.bulkIndex(ctx, index, documents)
+}
+
+// GetFeature the feature document for the station id
+func (s *ElasticStore) GetFeature(ctx context.Context, index string, ID string) (CachedFeature, bool, error) {
+
+	res, err := s.client.Get(index, ID, s.client.Get.WithContext(ctx))
+
+	if err != nil {
+		return CachedFeature{}, false, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return CachedFeature{}, false, nil
+	}
+
+	if res.IsError() {
+		return CachedFeature{}, false, fmt.Errorf("[%s] getting feature %s failed", res.Status(), ID)
+	}
+
+	var r struct {
+		Found  bool          `json:"found"`
+		Source CachedFeature `json:"_source"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return CachedFeature{}, false, err
+	}
+
+	return r.Source, r.Found, nil
+}
+
+// NearestStations the features within radiusKm of the point, closest first, using the location geo_point
+func (s *ElasticStore) NearestStations(ctx context.Context, index string, lat float64, lon float64, radiusKm float64, limit int) ([]StationDistance, error) {
+
+	point := GeoPoint{Lat: lat, Lon: lon}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"geo_distance": map[string]interface{}{
+						"distance": fmt.Sprintf("%fkm", radiusKm),
+						"location": point,
+					},
+				},
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{
+				"_geo_distance": map[string]interface{}{
+					"location":      point,
+					"order":         "asc",
+					"unit":          "km",
+					"distance_type": "arc",
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(&buf),
+		s.client.Search.WithSize(limit),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("[%s] searching near %f,%f failed", res.Status(), lat, lon)
+	}
+
+	var r struct {
+		Hits struct {
+			Hits []struct {
+				Source CachedFeature `json:"_source"`
+				Sort   []float64     `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	stations := make([]StationDistance, 0, len(r.Hits.Hits))
+
+	for _, hit := range r.Hits.Hits {
+		station := StationDistance{Feature: hit.Source.Feature}
+
+		if len(hit.Sort) > 0 {
+			station.DistanceKm = hit.Sort[0]
+		}
+
+		stations = append(stations, station)
+	}
+
+	return stations, nil
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"math"
+	"sort"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// earthRadiusKm the mean radius of the earth
+const earthRadiusKm = 6371.0088
+
+// StationDistance a station and how far it is from the searched point
+type StationDistance struct {
+	Feature    weather.Feature `json:"feature"`
+	DistanceKm float64         `json:"distanceKm"`
+}
+
+// Haversine the great circle distance in kilometers between two points
+func Haversine(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+
+	toRadians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// nearest the features within radiusKm of the point, closest first, at most limit of them.
+// Features without coordinates are skipped
+func nearest(features []weather.Feature, lat float64, lon float64, radiusKm float64, limit int) []StationDistance {
+
+	stations := make([]StationDistance, 0)
+
+	for _, feature := range features {
+		coordinates := feature.Geo.Coordinates
+
+		if len(coordinates) < 2 {
+			continue
+		}
+
+		distance := Haversine(lat, lon, coordinates[1], coordinates[0])
+
+		if distance <= radiusKm {
+			stations = append(stations, StationDistance{Feature: feature, DistanceKm: distance})
+		}
+	}
+
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].DistanceKm < stations[j].DistanceKm
+	})
+
+	if limit > 0 && len(stations) > limit {
+		stations = stations[:limit]
+	}
+
+	return stations
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+func TestHaversine(t *testing.T) {
+
+	// San Francisco International to Oakland International is about 17.3 km
+	distance := Haversine(37.61961, -122.36558, 37.72126, -122.22078)
+
+	if math.Abs(distance-17.3) > 0.5 {
+		t.Errorf("Distance not as expected. %f\n", distance)
+	}
+
+	if Haversine(10, 10, 10, 10) != 0 {
+		t.Errorf("Distance to the same point should be 0")
+	}
+}
+
+func TestNearestStations(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	store.InsertFeatures(context.Background(), "features", []weather.Feature{
+		{ID: "https://api.weather.gov/stations/KSFO", Geo: weather.Geometry{Type: "Point", Coordinates: []float64{-122.36558, 37.61961}}},
+		{ID: "https://api.weather.gov/stations/KOAK", Geo: weather.Geometry{Type: "Point", Coordinates: []float64{-122.22078, 37.72126}}},
+		{ID: "https://api.weather.gov/stations/KBOI", Geo: weather.Geometry{Type: "Point", Coordinates: []float64{-116.22278, 43.56444}}},
+		{ID: "https://api.weather.gov/stations/KNOP"},
+	})
+
+	stations, err := store.NearestStations(context.Background(), "features", 37.7, -122.3, 100, 10)
+
+	if err != nil {
+		t.Fatalf("Nearest stations failed. %+v\n", err)
+	}
+
+	if len(stations) != 2 {
+		t.Fatalf("Expected 2 stations within 100 km. %+v\n", stations)
+	}
+
+	if stations[0].Feature.ID != "https://api.weather.gov/stations/KOAK" || stations[0].DistanceKm > stations[1].DistanceKm {
+		t.Errorf("Stations not ordered by distance. %+v\n", stations)
+	}
+
+	stations, _ = store.NearestStations(context.Background(), "features", 37.7, -122.3, 1000, 1)
+
+	if len(stations) != 1 {
+		t.Errorf("Expected the limit of 1 station. %+v\n", stations)
+	}
+}
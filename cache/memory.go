@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// MemoryStore the Store kept in memory. Nothing survives a restart
+type MemoryStore struct {
+	mu       sync.RWMutex
+	stations map[string]map[string]string
+	features map[string]map[string]CachedFeature
+	alerts   map[string]map[string]weather.Alert
+
+	observations map[string]map[string]observationDocument
+	summaries    map[string]map[string]DailySummary
+}
+
+// NewMemoryStore create an empty in memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		stations: make(map[string]map[string]string),
+		features: make(map[string]map[string]CachedFeature),
+		alerts:   make(map[string]map[string]weather.Alert),
+
+		observations: make(map[string]map[string]observationDocument),
+		summaries:    make(map[string]map[string]DailySummary),
+	}
+}
+
+// IndexCount the number of documents in the index
+func (s *MemoryStore) IndexCount(ctx context.Context, index string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.stations[index]) + len(s.features[index]) + len(s.alerts[index]) + len(s.observations[index]) + len(s.summaries[index])), nil
+}
+
+// Contains the station id is in the stations index
+func (s *MemoryStore) Contains(ctx context.Context, index string, stationID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.stations[index][stationID]
+
+	return ok, nil
+}
+
+// ContainsFeature the station id is in the features index
+func (s *MemoryStore) ContainsFeature(ctx context.Context, index string, ID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.features[index][ID]
+
+	return ok, nil
+}
+
+// InsertStationList insert the station URLs into the index
+func (s *MemoryStore) InsertStationList(ctx context.Context, index string, stations []string) (BulkResult, error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stations[index] == nil {
+		s.stations[index] = make(map[string]string)
+	}
+
+	for _, station := range stations {
+		s.stations[index][documentID(station)] = station
+	}
+
+	return BulkResult{Indexed: uint64(len(stations)), Duration: time.Since(start)}, nil
+}
+
+// InsertFeatures insert the features into the index
+func (s *MemoryStore) InsertFeatures(ctx context.Context, index string, features []weather.Feature) (BulkResult, error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.features[index] == nil {
+		s.features[index] = make(map[string]CachedFeature)
+	}
+
+	cachedAt := time.Now().UTC()
+
+	for _, feature := range features {
+		s.features[index][documentID(feature.ID)] = CachedFeature{Feature: feature, CachedAt: cachedAt}
+	}
+
+	return BulkResult{Indexed: uint64(len(features)), Duration: time.Since(start)}, nil
+}
+
+// GetFeature the feature for the station id. False when it is not in the index
+func (s *MemoryStore) GetFeature(ctx context.Context, index string, ID string) (CachedFeature, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cached, ok := s.features[index][ID]
+
+	return cached, ok, nil
+}
+
+// NearestStations the features within radiusKm of the point, closest first
+func (s *MemoryStore) NearestStations(ctx context.Context, index string, lat float64, lon float64, radiusKm float64, limit int) ([]StationDistance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	features := make([]weather.Feature, 0, len(s.features[index]))
+
+	for _, cached := range s.features[index] {
+		features = append(features, cached.Feature)
+	}
+
+	return nearest(features, lat, lon, radiusKm, limit), nil
+}
+
+// InsertAlerts insert the alerts into the index
+func (s *MemoryStore) InsertAlerts(ctx context.Context, index string, alerts []weather.Alert) (BulkResult, error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.alerts[index] == nil {
+		s.alerts[index] = make(map[string]weather.Alert)
+	}
+
+	for _, alert := range alerts {
+		s.alerts[index][alert.ID] = alert
+	}
+
+	return BulkResult{Indexed: uint64(len(alerts)), Duration: time.Since(start)}, nil
+}
+
+// SearchAlerts the unexpired alerts in the index matching the filter
+func (s *MemoryStore) SearchAlerts(ctx context.Context, index string, filter AlertFilter) ([]weather.Alert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	alerts := make([]weather.Alert, 0)
+
+	for _, alert := range s.alerts[index] {
+		if filter.matches(alert, now) {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Props.Sent.After(alerts[j].Props.Sent)
+	})
+
+	return alerts, nil
+}
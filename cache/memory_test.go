@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+func TestMemoryStations(t *testing.T) {
+
+	store, err := NewStore(StoreConfig{Kind: StoreMemory})
+
+	if err != nil {
+		t.Fatalf("Failed creating the memory store. %+v\n", err)
+	}
+
+	store.InsertStationList(context.Background(), "stations", []string{
+		"https://api.weather.gov/stations/KSFO",
+		"https://api.weather.gov/stations/KCRG",
+	})
+
+	if found, _ := store.Contains(context.Background(), "stations", "KCRG"); !found {
+		t.Errorf("Should have found 'KCRG' in the store")
+	}
+
+	if found, _ := store.Contains(context.Background(), "stations", "edwinfailed"); found {
+		t.Errorf("Should NOT have found 'edwinfailed' in the store")
+	}
+
+	// a configured index name is the index checked
+	store.InsertStationList(context.Background(), "my-stations", []string{"https://api.weather.gov/stations/KBOI"})
+
+	if found, _ := store.Contains(context.Background(), "stations", "KBOI"); found {
+		t.Errorf("Should NOT have found 'KBOI' in the default index")
+	}
+
+	if found, _ := store.Contains(context.Background(), "my-stations", "KBOI"); !found {
+		t.Errorf("Should have found 'KBOI' in the configured index")
+	}
+
+	count, err := store.IndexCount(context.Background(), "stations")
+
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 stations. %d %+v\n", count, err)
+	}
+
+	stations, err := ListStations(store, "stations")
+
+	if err != nil || len(stations) != 2 || stations[0] != "https://api.weather.gov/stations/KCRG" {
+		t.Errorf("Station list not as expected. %v %+v\n", stations, err)
+	}
+}
+
+func TestMemoryFeatures(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	store.InsertFeatures(context.Background(), "features", []weather.Feature{
+		{ID: "https://api.weather.gov/stations/KEFK"},
+	})
+
+	if found, _ := store.ContainsFeature(context.Background(), "features", "KEFK"); !found {
+		t.Errorf("Should have found 'KEFK' in the store")
+	}
+
+	if found, _ := store.ContainsFeature(context.Background(), "features", "edwinfailed"); found {
+		t.Errorf("Should NOT have found 'edwinfailed' in the store")
+	}
+}
+
+func TestMemoryAlerts(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	active := weather.Alert{ID: "active"}
+	active.Props.Event = "Flood Warning"
+	active.Props.Severity = "Severe"
+	active.Props.Expires = time.Now().Add(time.Hour)
+	active.Props.Geocode.UGC = []string{"MDZ011"}
+
+	expired := active
+	expired.ID = "expired"
+	expired.Props.Expires = time.Now().Add(-time.Hour)
+
+	store.InsertAlerts(context.Background(), "alerts", []weather.Alert{active, expired})
+
+	alerts, err := store.SearchAlerts(context.Background(), "alerts", AlertFilter{State: "md", Severity: "severe"})
+
+	if err != nil || len(alerts) != 1 || alerts[0].ID != "active" {
+		t.Errorf("Expected only the active alert. %+v %+v\n", alerts, err)
+	}
+
+	alerts, _ = store.SearchAlerts(context.Background(), "alerts", AlertFilter{State: "VA"})
+
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alerts for VA. %+v\n", alerts)
+	}
+
+	alerts, _ = store.SearchAlerts(context.Background(), "alerts", AlertFilter{ByPoint: true, Lat: 39, Lon: -76})
+
+	if len(alerts) != 0 {
+		t.Errorf("Alerts without a geometry never contain a point. %+v\n", alerts)
+	}
+
+	// the outline of the alert's zones, as resolved when loading
+	zoned := active
+	zoned.ID = "zoned"
+	zoned.Geo = &weather.Shape{Type: "MultiPolygon", Polygons: [][][][]float64{{{{-77, 38}, {-75, 38}, {-75, 40}, {-77, 40}, {-77, 38}}}}}
+
+	store.InsertAlerts(context.Background(), "alerts", []weather.Alert{zoned})
+
+	alerts, _ = store.SearchAlerts(context.Background(), "alerts", AlertFilter{ByPoint: true, Lat: 39, Lon: -76})
+
+	if len(alerts) != 1 || alerts[0].ID != "zoned" {
+		t.Errorf("Expected the alert of the zone containing the point. %+v\n", alerts)
+	}
+
+	b, _ := json.Marshal(alertQuery(AlertFilter{ByPoint: true, Lat: 39, Lon: -76}))
+
+	if !strings.Contains(string(b), `"geo_shape":{"alert.geometry":{"relation":"intersects","shape":{"coordinates":[-76,39],"type":"point"}}}`) {
+		t.Errorf("Expected a geo_shape query for the point. %s\n", b)
+	}
+}
+
+func TestMemoryObservations(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	observation := weather.Observation{ID: "https://api.weather.gov/stations/KSFO/observations/2026-10-18T10:56:00+00:00"}
+	observation.Props.Station = "https://api.weather.gov/stations/KSFO"
+	observation.Props.Timestamp = time.Date(2026, 10, 18, 10, 56, 0, 0, time.UTC)
+	observation.Props.Temperature = weather.NewQuantity(14, weather.Celsius)
+	observation.Props.Dewpoint = weather.NewQuantity(11, weather.Celsius)
+
+	later := observation
+	later.Props.Timestamp = observation.Props.Timestamp.Add(time.Hour)
+
+	// loading the same observation again replaces it
+	store.InsertObservations(context.Background(), "observations", []weather.Observation{observation, later})
+	store.InsertObservations(context.Background(), "observations", []weather.Observation{observation})
+
+	count, err := store.IndexCount(context.Background(), "observations")
+
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 observations. %d %+v\n", count, err)
+	}
+
+	if id := observationID(observation); id != "KSFO_2026-10-18T10:56:00Z" {
+		t.Errorf("Observation id not as expected. %s\n", id)
+	}
+
+	if doc := store.observations["observations"][observationID(observation)]; doc.Derived.DewpointDepression == nil || *doc.Derived.DewpointDepression != 3 {
+		t.Errorf("Expected the derived metrics stored with the observation. %+v\n", doc.Derived)
+	}
+}
+
+func TestLookupFeature(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	calls := 0
+
+	fetch := func(stationID string) (weather.Feature, error) {
+		calls++
+		return weather.Feature{ID: "https://api.weather.gov/stations/" + stationID}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		feature, err := LookupFeature(store, "features", "KBOI", time.Hour, fetch)
+
+		if err != nil || feature.ID != "https://api.weather.gov/stations/KBOI" {
+			t.Errorf("Feature not as expected. %+v %+v\n", feature, err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("The weather service should be called once, then read from the cache. %d\n", calls)
+	}
+
+	// a stale feature is fetched again
+	store.features["features"]["KBOI"] = CachedFeature{
+		Feature:  weather.Feature{ID: "https://api.weather.gov/stations/KBOI"},
+		CachedAt: time.Now().Add(-2 * time.Hour),
+	}
+
+	LookupFeature(store, "features", "KBOI", time.Hour, fetch)
+
+	if calls != 2 {
+		t.Errorf("A stale feature should call the weather service. %d\n", calls)
+	}
+
+	cached, found, _ := store.GetFeature(context.Background(), "features", "KBOI")
+
+	if !found || time.Since(cached.CachedAt) > time.Minute {
+		t.Errorf("The refreshed feature should be written back. %+v\n", cached)
+	}
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+	"github.com/EdSwArchitect/go-weather/weather/derive"
+)
+
+// ObservationDateFormat the date at the end of a daily observation index name
+const ObservationDateFormat = "2006.01.02"
+
+// ObservationIndex the daily index for observations at the time, e.g. observations-2026.10.18
+func ObservationIndex(prefix string, t time.Time) string {
+	return prefix + "-" + t.UTC().Format(ObservationDateFormat)
+}
+
+// observationDocument the observation as stored, with the fields the observations template maps and
+// the metrics derived from it
+type observationDocument struct {
+	Observation weather.Observation `json:"observation"`
+	StationID   string              `json:"stationId"`
+	Timestamp   time.Time           `json:"timestamp"`
+	Location    *GeoPoint           `json:"location,omitempty"`
+	Derived     derive.Metrics      `json:"derived"`
+}
+
+func newObservationDocument(observation weather.Observation) observationDocument {
+
+	doc := observationDocument{
+		Observation: observation,
+		StationID:   observation.StationID(),
+		Timestamp:   observation.Props.Timestamp.UTC(),
+		Derived:     derive.Observation(observation),
+	}
+
+	if coordinates := observation.Geo.Coordinates; len(coordinates) >= 2 {
+		doc.Location = &GeoPoint{Lat: coordinates[1], Lon: coordinates[0]}
+	}
+
+	return doc
+}
+
+// observationID the station and time of the observation, so loading it again replaces it
+func observationID(observation weather.Observation) string {
+	return observation.StationID() + "_" + observation.Props.Timestamp.UTC().Format(time.RFC3339)
+}
+
+// InsertObservations into the daily Elastic indices for their timestamps. The index is the prefix
+// of the daily indices, which are read through the alias of the same name
+func (s *ElasticStore) InsertObservations(ctx context.Context, index string, observations []weather.Observation) (BulkResult, error) {
+
+	documents := make([]bulkDocument, 0, len(observations))
+
+	for _, observation := range observations {
+
+		b, err := json.Marshal(newObservationDocument(observation))
+
+		if err != nil {
+			return BulkResult{}, fmt.Errorf("Unable to marshall observation %s: %s", observation.ID, err)
+		}
+
+		documents = append(documents, bulkDocument{
+			ID:    observationID(observation),
+			Body:  b,
+			Index: ObservationIndex(index, observation.Props.Timestamp),
+		})
+	}
+
+	return s.bulkIndex(ctx, index, documents)
+}
+
+// InsertObservations insert the observations into the index
+func (s *MemoryStore) InsertObservations(ctx context.Context, index string, observations []weather.Observation) (BulkResult, error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.observations[index] == nil {
+		s.observations[index] = make(map[string]observationDocument)
+	}
+
+	for _, observation := range observations {
+		s.observations[index][observationID(observation)] = newObservationDocument(observation)
+	}
+
+	return BulkResult{Indexed: uint64(len(observations)), Duration: time.Since(start)}, nil
+}
+
+// InsertObservations insert the observations into the index
+func (s *BoltStore) InsertObservations(ctx context.Context, index string, observations []weather.Observation) (BulkResult, error) {
+
+	values := make(map[string]interface{}, len(observations))
+
+	for _, observation := range observations {
+		values[observationID(observation)] = newObservationDocument(observation)
+	}
+
+	return s.put(observationsBucket(index), values)
+}
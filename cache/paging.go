@@ -0,0 +1,343 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultPageSize the station URLs read per page when streaming the stations index
+const DefaultPageSize = 1000
+
+// StationPage a page of station URLs. Next is empty on the last page
+type StationPage struct {
+	Stations []string `json:"stations"`
+	Next     string   `json:"next,omitempty"`
+}
+
+// ErrInvalidCursor the page cursor was not returned by a previous page
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor the sort values of the last document on a page as an opaque cursor
+func encodeCursor(values []interface{}) string {
+	b, _ := json.Marshal(values)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor the sort values in the cursor. An empty cursor is the first page
+func decodeCursor(cursor string) ([]interface{}, error) {
+
+	if cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	var values []interface{}
+
+	if err := json.Unmarshal(b, &values); err != nil || len(values) == 0 {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	return values, nil
+}
+
+// cursorID the station id the memory and bolt stores keep as the last sort value of the cursor
+func cursorID(cursor string) (string, error) {
+
+	values, err := decodeCursor(cursor)
+
+	if err != nil || values == nil {
+		return "", err
+	}
+
+	id, ok := values[len(values)-1].(string)
+
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	return id, nil
+}
+
+// StationIterator streams the station URLs in an index a page at a time
+//
+//	it := NewStationIterator(store, "stations", 0)
+//	for it.Next() {
+//		station := it.Station()
+//	}
+//	if err := it.Err(); err != nil {
+type StationIterator struct {
+	ctx      context.Context
+	store    Store
+	index    string
+	pageSize int
+	page     StationPage
+	i        int
+	started  bool
+	err      error
+}
+
+// NewStationIterator iterate the station URLs in the index. A pageSize of zero is DefaultPageSize
+func NewStationIterator(store Store, index string, pageSize int) *StationIterator {
+	return NewStationIteratorContext(context.Background(), store, index, pageSize)
+}
+
+// NewStationIteratorContext iterate the station URLs in the index until the context is done
+func NewStationIteratorContext(ctx context.Context, store Store, index string, pageSize int) *StationIterator {
+
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return &StationIterator{ctx: ctx, store: store, index: index, pageSize: pageSize, i: -1}
+}
+
+// Next advance to the next station. False when there are no more or on an error
+func (it *StationIterator) Next() bool {
+
+	if it.err != nil {
+		return false
+	}
+
+	it.i++
+
+	for it.i >= len(it.page.Stations) {
+
+		if it.started && it.page.Next == "" {
+			return false
+		}
+
+		it.page, it.err = it.store.GetStationPage(it.ctx, it.index, it.page.Next, it.pageSize)
+		it.started = true
+		it.i = 0
+
+		if it.err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Station the current station URL
+func (it *StationIterator) Station() string {
+	return it.page.Stations[it.i]
+}
+
+// Err the error that stopped the iteration, if any
+func (it *StationIterator) Err() error {
+	return it.err
+}
+
+// ListStations every station URL in the index
+func ListStations(store Store, index string) ([]string, error) {
+	return ListStationsContext(context.Background(), store, index)
+}
+
+// ListStationsContext every station URL in the index, stopping when the context is done
+func ListStationsContext(ctx context.Context, store Store, index string) ([]string, error) {
+
+	stations := make([]string, 0)
+
+	it := NewStationIteratorContext(ctx, store, index, 0)
+
+	for it.Next() {
+		stations = append(stations, it.Station())
+	}
+
+	return stations, it.Err()
+}
+
+// GetStationPage a page of station URLs ordered by station URL, after the cursor. search_after
+// has no result window limit, unlike from and size. The pages are not read from a point in time, the
+// ElasticSearch 7.7 this runs against and its client have none, so a station loaded while a client is
+// paging shows on a later page only when it sorts after the cursor. _id breaks ties so no station is
+// repeated or skipped when two share a URL
+func (s *ElasticStore) GetStationPage(ctx context.Context, index string, cursor string, size int) (StationPage, error) {
+
+	after, err := decodeCursor(cursor)
+
+	if err != nil {
+		return StationPage{}, err
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"station": "asc"},
+			map[string]interface{}{"_id": "asc"},
+		},
+	}
+
+	// a cursor holds a value for each sort
+	if after != nil && len(after) != 2 {
+		return StationPage{}, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	if after != nil {
+		query["search_after"] = after
+	}
+
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return StationPage{}, err
+	}
+
+	// one more than the page to know if there is a next page
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(&buf),
+		s.client.Search.WithSize(size+1),
+	)
+
+	if err != nil {
+		return StationPage{}, err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		var e map[string]interface{}
+		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
+			return StationPage{}, err
+		}
+
+		return StationPage{}, fmt.Errorf("[%s] %v", res.Status(), e["error"])
+	}
+
+	var r struct {
+		Hits struct {
+			Hits []struct {
+				Source struct {
+					Station string `json:"station"`
+				} `json:"_source"`
+				Sort []interface{} `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return StationPage{}, err
+	}
+
+	hits := r.Hits.Hits
+	page := StationPage{Stations: make([]string, 0, size)}
+
+	if len(hits) > size {
+		hits = hits[:size]
+		page.Next = encodeCursor(hits[size-1].Sort)
+	}
+
+	for _, hit := range hits {
+		page.Stations = append(page.Stations, hit.Source.Station)
+	}
+
+	return page, nil
+}
+
+// GetStationPage a page of station URLs ordered by station id, after the cursor
+func (s *MemoryStore) GetStationPage(ctx context.Context, index string, cursor string, size int) (StationPage, error) {
+
+	after, err := cursorID(cursor)
+
+	if err != nil {
+		return StationPage{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.stations[index]))
+
+	for id := range s.stations[index] {
+		if after == "" || id > after {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+
+	page := StationPage{Stations: make([]string, 0, size)}
+
+	if len(ids) > size {
+		ids = ids[:size]
+		page.Next = encodeCursor([]interface{}{ids[size-1]})
+	}
+
+	for _, id := range ids {
+		page.Stations = append(page.Stations, s.stations[index][id])
+	}
+
+	return page, nil
+}
+
+// GetStationPage a page of station URLs ordered by station id, after the cursor
+func (s *BoltStore) GetStationPage(ctx context.Context, index string, cursor string, size int) (StationPage, error) {
+
+	after, err := cursorID(cursor)
+
+	if err != nil {
+		return StationPage{}, err
+	}
+
+	page := StationPage{Stations: make([]string, 0, size)}
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(stationsBucket(index))
+
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+
+		k, v := c.First()
+
+		if after != "" {
+			k, v = c.Seek([]byte(after))
+
+			if k != nil && string(k) == after {
+				k, v = c.Next()
+			}
+		}
+
+		last := ""
+
+		for ; k != nil; k, v = c.Next() {
+
+			if len(page.Stations) == size {
+				page.Next = encodeCursor([]interface{}{last})
+				break
+			}
+
+			var station string
+
+			if err := json.Unmarshal(v, &station); err != nil {
+				return err
+			}
+
+			page.Stations = append(page.Stations, station)
+			last = string(k)
+		}
+
+		return nil
+	})
+
+	return page, err
+}
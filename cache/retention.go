@@ -0,0 +1,458 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+	"github.com/dustin/go-humanize"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Retention how long each kind of time series data is kept. Zero keeps it forever
+type Retention struct {
+	// Observations by the observation timestamp. Daily indices are deleted once the whole day is older
+	Observations time.Duration
+	// Alerts by when the alert expired
+	Alerts time.Duration
+	// DailySummaries by the summary date. A day is deleted once the whole day is older
+	DailySummaries time.Duration
+}
+
+// RetentionResult what ApplyRetention deleted
+type RetentionResult struct {
+	DeletedIndices   []string `json:"deletedIndices,omitempty"`
+	DeletedDocuments int64    `json:"deletedDocuments"`
+}
+
+// IndexStat the size of an index
+type IndexStat struct {
+	Index     string `json:"index"`
+	Health    string `json:"health,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Documents int64  `json:"documents"`
+	SizeBytes int64  `json:"sizeBytes"`
+	Size      string `json:"size"`
+}
+
+func newIndexStat(index string, documents int64, sizeBytes int64) IndexStat {
+	return IndexStat{
+		Index:     index,
+		Documents: documents,
+		SizeBytes: sizeBytes,
+		Size:      humanize.Bytes(uint64(sizeBytes)),
+	}
+}
+
+// cutoff the time before which data is deleted, zero when it is kept forever
+func cutoff(now time.Time, maxAge time.Duration) time.Time {
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+
+	return now.Add(-maxAge)
+}
+
+// catIndex a row of the cat indices API. The numbers are strings
+type catIndex struct {
+	Index     string `json:"index"`
+	Health    string `json:"health"`
+	Status    string `json:"status"`
+	Documents string `json:"docs.count"`
+	Size      string `json:"store.size"`
+}
+
+// catIndices every index in the cluster, with its size in bytes
+func (s *ElasticStore) catIndices(ctx context.Context) ([]catIndex, error) {
+
+	res, err := s.client.Cat.Indices(
+		s.client.Cat.Indices.WithContext(ctx),
+		s.client.Cat.Indices.WithFormat("json"),
+		s.client.Cat.Indices.WithBytes("b"),
+		s.client.Cat.Indices.WithH("index", "health", "status", "docs.count", "store.size"),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("[%s] listing the indices failed", res.Status())
+	}
+
+	var rows []catIndex
+
+	if err = json.NewDecoder(res.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// isObservationIndex the index is one of the daily observation indices
+func isObservationIndex(prefix string, index string) bool {
+	_, err := time.Parse(ObservationDateFormat, strings.TrimPrefix(index, prefix+"-"))
+
+	return strings.HasPrefix(index, prefix+"-") && err == nil
+}
+
+// IndexStats the go-weather indices with their document counts and sizes, by name
+func (s *ElasticStore) IndexStats(ctx context.Context, indices Indices) ([]IndexStat, error) {
+
+	rows, err := s.catIndices(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]IndexStat, 0)
+
+	for _, row := range rows {
+
+		switch {
+		case row.Index == indices.Stations, row.Index == indices.Features, row.Index == indices.Alerts, row.Index == indices.DailySummaries:
+		case isObservationIndex(indices.Observations, row.Index):
+		default:
+			continue
+		}
+
+		// a closed index has no counts
+		documents, _ := strconv.ParseInt(row.Documents, 10, 64)
+		size, _ := strconv.ParseInt(row.Size, 10, 64)
+
+		stat := newIndexStat(row.Index, documents, size)
+		stat.Health = row.Health
+		stat.Status = row.Status
+
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Index < stats[j].Index
+	})
+
+	return stats, nil
+}
+
+// summariesBefore the first summary date kept, summaries of earlier days are deleted
+func summariesBefore(before time.Time) string {
+	return before.UTC().Format(SummaryDateFormat)
+}
+
+// ApplyRetention delete the daily observation indices, the alerts and the daily summaries older than
+// the retention
+func (s *ElasticStore) ApplyRetention(ctx context.Context, indices Indices, retention Retention, now time.Time) (RetentionResult, error) {
+
+	var result RetentionResult
+
+	if before := cutoff(now, retention.Observations); !before.IsZero() {
+
+		rows, err := s.catIndices(ctx)
+
+		if err != nil {
+			return result, err
+		}
+
+		for _, row := range rows {
+			if !isObservationIndex(indices.Observations, row.Index) {
+				continue
+			}
+
+			day, _ := time.Parse(ObservationDateFormat, strings.TrimPrefix(row.Index, indices.Observations+"-"))
+
+			if !day.AddDate(0, 0, 1).After(before) {
+				result.DeletedIndices = append(result.DeletedIndices, row.Index)
+			}
+		}
+
+		if len(result.DeletedIndices) > 0 {
+			if err = s.deleteIndices(ctx, result.DeletedIndices); err != nil {
+				return RetentionResult{}, err
+			}
+
+			log.Printf("Deleted the expired indices %v", result.DeletedIndices)
+		}
+	}
+
+	if before := cutoff(now, retention.Alerts); !before.IsZero() {
+
+		deleted, err := s.deleteByQuery(ctx, indices.Alerts, map[string]interface{}{
+			"range": map[string]interface{}{
+				"alert.properties.expires": map[string]interface{}{"lt": before.UTC().Format(time.RFC3339)},
+			},
+		})
+
+		if err != nil {
+			return result, err
+		}
+
+		result.DeletedDocuments += deleted
+	}
+
+	if before := cutoff(now, retention.DailySummaries); !before.IsZero() {
+
+		deleted, err := s.deleteByQuery(ctx, indices.DailySummaries, map[string]interface{}{
+			"range": map[string]interface{}{
+				"date": map[string]interface{}{"lt": summariesBefore(before)},
+			},
+		})
+
+		if err != nil {
+			return result, err
+		}
+
+		result.DeletedDocuments += deleted
+	}
+
+	return result, nil
+}
+
+func (s *ElasticStore) deleteIndices(ctx context.Context, names []string) error {
+
+	res, err := s.client.Indices.Delete(names, s.client.Indices.Delete.WithContext(ctx))
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("[%s] deleting %v failed", res.Status(), names)
+	}
+
+	return nil
+}
+
+// deleteByQuery delete the documents matching the query, returning how many were deleted
+func (s *ElasticStore) deleteByQuery(ctx context.Context, index string, query map[string]interface{}) (int64, error) {
+
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"query": query}); err != nil {
+		return 0, err
+	}
+
+	res, err := s.client.DeleteByQuery(
+		[]string{index},
+		&buf,
+		s.client.DeleteByQuery.WithContext(ctx),
+		s.client.DeleteByQuery.WithConflicts("proceed"),
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+
+	if res.IsError() {
+		return 0, fmt.Errorf("[%s] deleting from %s failed", res.Status(), index)
+	}
+
+	var deleted struct {
+		Deleted int64 `json:"deleted"`
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&deleted); err != nil {
+		return 0, err
+	}
+
+	return deleted.Deleted, nil
+}
+
+// IndexStats the document count of each index. Memory has no size
+func (s *MemoryStore) IndexStats(ctx context.Context, indices Indices) ([]IndexStat, error) {
+
+	stats := make([]IndexStat, 0, 5)
+
+	for _, index := range []string{indices.Alerts, indices.DailySummaries, indices.Features, indices.Observations, indices.Stations} {
+		count, _ := s.IndexCount(ctx, index)
+		stats = append(stats, newIndexStat(index, count, 0))
+	}
+
+	return stats, nil
+}
+
+// ApplyRetention delete the observations, alerts and daily summaries older than the retention
+func (s *MemoryStore) ApplyRetention(ctx context.Context, indices Indices, retention Retention, now time.Time) (RetentionResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result RetentionResult
+
+	if before := cutoff(now, retention.Observations); !before.IsZero() {
+		for id, doc := range s.observations[indices.Observations] {
+			if doc.Timestamp.Before(before) {
+				delete(s.observations[indices.Observations], id)
+				result.DeletedDocuments++
+			}
+		}
+	}
+
+	if before := cutoff(now, retention.Alerts); !before.IsZero() {
+		for id, alert := range s.alerts[indices.Alerts] {
+			if alert.Props.Expires.Before(before) {
+				delete(s.alerts[indices.Alerts], id)
+				result.DeletedDocuments++
+			}
+		}
+	}
+
+	if before := cutoff(now, retention.DailySummaries); !before.IsZero() {
+		for id, summary := range s.summaries[indices.DailySummaries] {
+			if summary.Date < summariesBefore(before) {
+				delete(s.summaries[indices.DailySummaries], id)
+				result.DeletedDocuments++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// IndexStats the document count and the bytes used by each index
+func (s *BoltStore) IndexStats(ctx context.Context, indices Indices) ([]IndexStat, error) {
+
+	stats := make([]IndexStat, 0, 5)
+
+	buckets := map[string][]byte{
+		indices.Stations:       stationsBucket(indices.Stations),
+		indices.Features:       featuresBucket(indices.Features),
+		indices.Alerts:         alertsBucket(indices.Alerts),
+		indices.Observations:   observationsBucket(indices.Observations),
+		indices.DailySummaries: summaryBucket(indices.DailySummaries),
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for index, bucket := range buckets {
+			stat := newIndexStat(index, 0, 0)
+
+			if b := tx.Bucket(bucket); b != nil {
+				bs := b.Stats()
+				stat = newIndexStat(index, int64(bs.KeyN), int64(bs.BranchInuse+bs.LeafInuse+bs.InlineBucketInuse))
+			}
+
+			stats = append(stats, stat)
+		}
+
+		return nil
+	})
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Index < stats[j].Index
+	})
+
+	return stats, err
+}
+
+// ApplyRetention delete the observations, alerts and daily summaries older than the retention, in one
+// transaction
+func (s *BoltStore) ApplyRetention(ctx context.Context, indices Indices, retention Retention, now time.Time) (RetentionResult, error) {
+
+	var result RetentionResult
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+
+		if before := cutoff(now, retention.Observations); !before.IsZero() {
+			deleted, err := deleteWhere(tx, observationsBucket(indices.Observations), func(v []byte) (bool, error) {
+				var doc observationDocument
+				err := json.Unmarshal(v, &doc)
+
+				return err == nil && doc.Timestamp.Before(before), err
+			})
+
+			if err != nil {
+				return err
+			}
+
+			result.DeletedDocuments += deleted
+		}
+
+		if before := cutoff(now, retention.Alerts); !before.IsZero() {
+			deleted, err := deleteWhere(tx, alertsBucket(indices.Alerts), func(v []byte) (bool, error) {
+				var alert weather.Alert
+				err := json.Unmarshal(v, &alert)
+
+				return err == nil && alert.Props.Expires.Before(before), err
+			})
+
+			if err != nil {
+				return err
+			}
+
+			result.DeletedDocuments += deleted
+		}
+
+		if before := cutoff(now, retention.DailySummaries); !before.IsZero() {
+			deleted, err := deleteWhere(tx, summaryBucket(indices.DailySummaries), func(v []byte) (bool, error) {
+				var summary DailySummary
+				err := json.Unmarshal(v, &summary)
+
+				return err == nil && summary.Date < summariesBefore(before), err
+			})
+
+			if err != nil {
+				return err
+			}
+
+			result.DeletedDocuments += deleted
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return RetentionResult{}, err
+	}
+
+	return result, nil
+}
+
+// deleteWhere delete the keys in the bucket whose values match
+func deleteWhere(tx *bolt.Tx, bucket []byte, match func(v []byte) (bool, error)) (int64, error) {
+
+	b := tx.Bucket(bucket)
+
+	if b == nil {
+		return 0, nil
+	}
+
+	var keys [][]byte
+
+	err := b.ForEach(func(k []byte, v []byte) error {
+		matched, err := match(v)
+
+		if matched {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, k := range keys {
+		if err = b.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(keys)), nil
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// agedData an observation and an alert from 10 days before now, and another of each from now
+func agedData(now time.Time) ([]weather.Observation, []weather.Alert) {
+
+	old := weather.Observation{}
+	old.Props.Station = "https://api.weather.gov/stations/KSFO"
+	old.Props.Timestamp = now.AddDate(0, 0, -10)
+
+	recent := old
+	recent.Props.Timestamp = now
+
+	expired := weather.Alert{ID: "expired"}
+	expired.Props.Expires = now.AddDate(0, 0, -10)
+
+	active := weather.Alert{ID: "active"}
+	active.Props.Expires = now.Add(time.Hour)
+
+	return []weather.Observation{old, recent}, []weather.Alert{expired, active}
+}
+
+func testRetention(t *testing.T, store Store) {
+
+	ctx := context.Background()
+	now := time.Now()
+
+	observations, alerts := agedData(now)
+
+	store.InsertObservations(ctx, DefaultIndices.Observations, observations)
+	store.InsertAlerts(ctx, DefaultIndices.Alerts, alerts)
+	store.InsertDailySummaries(ctx, DefaultIndices.DailySummaries, []DailySummary{
+		{StationID: "KSFO", Date: now.AddDate(0, 0, -10).UTC().Format(SummaryDateFormat)},
+		{StationID: "KSFO", Date: now.UTC().Format(SummaryDateFormat)},
+	})
+
+	// zero keeps everything
+	result, err := store.ApplyRetention(ctx, DefaultIndices, Retention{}, now)
+
+	if err != nil || result.DeletedDocuments != 0 {
+		t.Errorf("Expected nothing deleted. %+v %+v\n", result, err)
+	}
+
+	result, err = store.ApplyRetention(ctx, DefaultIndices, Retention{Observations: 7 * 24 * time.Hour, Alerts: 24 * time.Hour, DailySummaries: 7 * 24 * time.Hour}, now)
+
+	if err != nil || result.DeletedDocuments != 3 {
+		t.Errorf("Expected the old observation, alert and summary deleted. %+v %+v\n", result, err)
+	}
+
+	stats, err := store.IndexStats(ctx, DefaultIndices)
+
+	if err != nil || len(stats) != 5 {
+		t.Fatalf("Expected stats for the 5 indices. %+v %+v\n", stats, err)
+	}
+
+	for _, stat := range stats {
+		if (stat.Index == "observations" || stat.Index == "alerts" || stat.Index == "daily-summaries") && stat.Documents != 1 {
+			t.Errorf("Expected 1 document left. %+v\n", stat)
+		}
+	}
+}
+
+func TestMemoryRetention(t *testing.T) {
+	testRetention(t, NewMemoryStore())
+}
+
+func TestBoltRetention(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-weather")
+
+	if err != nil {
+		t.Fatalf("Failed creating temp dir. %+v\n", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	store, err := NewBoltStore(filepath.Join(dir, "go-weather.db"))
+
+	if err != nil {
+		t.Fatalf("Failed opening the bolt store. %+v\n", err)
+	}
+
+	defer store.Close()
+
+	testRetention(t, store)
+}
+
+func TestIsObservationIndex(t *testing.T) {
+
+	cases := map[string]bool{
+		"observations-2026.10.18": true,
+		"observations-2026.13.01": false,
+		"observations":            false,
+		"observations-old":        false,
+		"features":                false,
+	}
+
+	for index, expected := range cases {
+		if isObservationIndex("observations", index) != expected {
+			t.Errorf("isObservationIndex %s not %t\n", index, expected)
+		}
+	}
+}
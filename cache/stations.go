@@ -0,0 +1,352 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// BoundingBox a longitude and latitude box, in the GeoJSON bbox order
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// ParseBoundingBox parse minLon,minLat,maxLon,maxLat
+func ParseBoundingBox(bbox string) (BoundingBox, error) {
+
+	parts := strings.Split(bbox, ",")
+
+	if len(parts) != 4 {
+		return BoundingBox{}, fmt.Errorf("Invalid bbox %q, expected minLon,minLat,maxLon,maxLat", bbox)
+	}
+
+	var values [4]float64
+
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("Invalid bbox %q, expected minLon,minLat,maxLon,maxLat", bbox)
+		}
+
+		values[i] = value
+	}
+
+	box := BoundingBox{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+
+	if box.MinLat > box.MaxLat || box.MinLat < -90 || box.MaxLat > 90 || box.MinLon < -180 || box.MaxLon > 180 {
+		return BoundingBox{}, fmt.Errorf("Invalid bbox %q", bbox)
+	}
+
+	return box, nil
+}
+
+// Contains the point is inside the box. A box crossing the antimeridian has MinLon > MaxLon
+func (b BoundingBox) Contains(lat float64, lon float64) bool {
+
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+
+	if b.MinLon <= b.MaxLon {
+		return lon >= b.MinLon && lon <= b.MaxLon
+	}
+
+	return lon >= b.MinLon || lon <= b.MaxLon
+}
+
+// StationFilter limits the stations returned by SearchStations. Empty fields are not filtered on
+type StationFilter struct {
+	BBox     *BoundingBox
+	State    string
+	County   string // the county zone ID, e.g. CAC081
+	TimeZone string
+	Name     string // full text, every word must match
+	Limit    int    // the page size, zero is DefaultPageSize
+	Cursor   string // the next cursor of the previous page
+}
+
+// MaxPageSize the most stations returned in a page
+const MaxPageSize = 5000
+
+// FeaturePage a page of stations. Next is empty on the last page
+type FeaturePage struct {
+	Features []weather.Feature `json:"stations"`
+	Next     string            `json:"next,omitempty"`
+}
+
+// pageSize the filter's page size within the limits
+func (filter StationFilter) pageSize() int {
+
+	if filter.Limit <= 0 {
+		return DefaultPageSize
+	}
+
+	if filter.Limit > MaxPageSize {
+		return MaxPageSize
+	}
+
+	return filter.Limit
+}
+
+// pageFeatures the page of the sorted features passing the filter, after the filter's cursor
+func pageFeatures(features []weather.Feature, filter StationFilter) (FeaturePage, error) {
+
+	after, err := cursorID(filter.Cursor)
+
+	if err != nil {
+		return FeaturePage{}, err
+	}
+
+	size := filter.pageSize()
+	page := FeaturePage{Features: make([]weather.Feature, 0)}
+
+	for _, feature := range features {
+
+		if after != "" && feature.StationID() <= after {
+			continue
+		}
+
+		if !filter.Match(feature) {
+			continue
+		}
+
+		if len(page.Features) == size {
+			page.Next = encodeCursor([]interface{}{page.Features[size-1].StationID()})
+			break
+		}
+
+		page.Features = append(page.Features, feature)
+	}
+
+	return page, nil
+}
+
+// Match the feature passes the filter
+func (filter StationFilter) Match(feature weather.Feature) bool {
+
+	if filter.BBox != nil {
+		coordinates := feature.Geo.Coordinates
+
+		if len(coordinates) < 2 || !filter.BBox.Contains(coordinates[1], coordinates[0]) {
+			return false
+		}
+	}
+
+	if filter.State != "" && !strings.EqualFold(feature.State(), filter.State) {
+		return false
+	}
+
+	if filter.County != "" && !strings.EqualFold(feature.CountyZone(), filter.County) {
+		return false
+	}
+
+	if filter.TimeZone != "" && feature.Props.TimeZone != filter.TimeZone {
+		return false
+	}
+
+	if filter.Name != "" {
+		name := strings.ToLower(feature.Props.Name)
+
+		for _, word := range strings.Fields(strings.ToLower(filter.Name)) {
+			if !strings.Contains(name, strings.Trim(word, ",.")) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// sortFeatures order the features by station id
+func sortFeatures(features []weather.Feature) {
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].StationID() < features[j].StationID()
+	})
+}
+
+// stationQuery the Elastic query for the filter
+func stationQuery(filter StationFilter) (map[string]interface{}, error) {
+
+	filters := make([]interface{}, 0)
+
+	if filter.BBox != nil {
+		filters = append(filters, map[string]interface{}{
+			"geo_bounding_box": map[string]interface{}{
+				"location": map[string]interface{}{
+					"top_left":     GeoPoint{Lat: filter.BBox.MaxLat, Lon: filter.BBox.MinLon},
+					"bottom_right": GeoPoint{Lat: filter.BBox.MinLat, Lon: filter.BBox.MaxLon},
+				},
+			},
+		})
+	}
+
+	if filter.State != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"state": strings.ToUpper(filter.State)},
+		})
+	}
+
+	if filter.County != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"county": strings.ToUpper(filter.County)},
+		})
+	}
+
+	if filter.TimeZone != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"feature.properties.timeZone": filter.TimeZone},
+		})
+	}
+
+	boolQuery := map[string]interface{}{"filter": filters}
+
+	// _id breaks ties, there is no point in time to page through
+	sort := []interface{}{map[string]interface{}{"stationId": "asc"}, map[string]interface{}{"_id": "asc"}}
+
+	if filter.Name != "" {
+		boolQuery["must"] = map[string]interface{}{
+			"match": map[string]interface{}{
+				"feature.properties.name": map[string]interface{}{"query": filter.Name, "operator": "and"},
+			},
+		}
+
+		sort = []interface{}{"_score", map[string]interface{}{"stationId": "asc"}, map[string]interface{}{"_id": "asc"}}
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{"bool": boolQuery},
+		"sort":  sort,
+	}
+
+	after, err := decodeCursor(filter.Cursor)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a cursor holds a value for each sort
+	if after != nil && len(after) != len(sort) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCursor, filter.Cursor)
+	}
+
+	if after != nil {
+		query["search_after"] = after
+	}
+
+	return query, nil
+}
+
+// SearchStations a page of the features in the index passing the filter
+func (s *ElasticStore) SearchStations(ctx context.Context, index string, filter StationFilter) (FeaturePage, error) {
+
+	query, err := stationQuery(filter)
+
+	if err != nil {
+		return FeaturePage{}, err
+	}
+
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return FeaturePage{}, err
+	}
+
+	size := filter.pageSize()
+
+	// one more than the page to know if there is a next page
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(&buf),
+		s.client.Search.WithSize(size+1),
+	)
+
+	if err != nil {
+		return FeaturePage{}, err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return FeaturePage{}, fmt.Errorf("[%s] searching stations failed", res.Status())
+	}
+
+	var r struct {
+		Hits struct {
+			Hits []struct {
+				Source CachedFeature `json:"_source"`
+				Sort   []interface{} `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return FeaturePage{}, err
+	}
+
+	hits := r.Hits.Hits
+	page := FeaturePage{Features: make([]weather.Feature, 0, len(hits))}
+
+	if len(hits) > size {
+		hits = hits[:size]
+		page.Next = encodeCursor(hits[size-1].Sort)
+	}
+
+	for _, hit := range hits {
+		page.Features = append(page.Features, hit.Source.Feature)
+	}
+
+	return page, nil
+}
+
+// SearchStations a page of the features in the index passing the filter, ordered by station id
+func (s *MemoryStore) SearchStations(ctx context.Context, index string, filter StationFilter) (FeaturePage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	features := make([]weather.Feature, 0, len(s.features[index]))
+
+	for _, cached := range s.features[index] {
+		features = append(features, cached.Feature)
+	}
+
+	sortFeatures(features)
+
+	return pageFeatures(features, filter)
+}
+
+// SearchStations a page of the features in the index passing the filter, ordered by station id
+func (s *BoltStore) SearchStations(ctx context.Context, index string, filter StationFilter) (FeaturePage, error) {
+
+	features := make([]weather.Feature, 0)
+
+	err := s.each(ctx, featuresBucket(index), func(k []byte, v []byte) error {
+		var cached CachedFeature
+
+		if err := json.Unmarshal(v, &cached); err != nil {
+			return err
+		}
+
+		features = append(features, cached.Feature)
+
+		return nil
+	})
+
+	if err != nil {
+		return FeaturePage{}, err
+	}
+
+	sortFeatures(features)
+
+	return pageFeatures(features, filter)
+}
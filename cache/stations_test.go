@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+var testFeatures = []weather.Feature{
+	{
+		ID:  "https://api.weather.gov/stations/KSFO",
+		Geo: weather.Geometry{Type: "Point", Coordinates: []float64{-122.36558, 37.61961}},
+		Props: weather.Properties{
+			StationID: "KSFO",
+			Name:      "San Francisco, San Francisco International Airport",
+			TimeZone:  "America/Los_Angeles",
+			County:    "https://api.weather.gov/zones/county/CAC081",
+		},
+	},
+	{
+		ID:  "https://api.weather.gov/stations/KOAK",
+		Geo: weather.Geometry{Type: "Point", Coordinates: []float64{-122.22078, 37.72126}},
+		Props: weather.Properties{
+			StationID: "KOAK",
+			Name:      "Oakland International Airport",
+			TimeZone:  "America/Los_Angeles",
+			County:    "https://api.weather.gov/zones/county/CAC001",
+		},
+	},
+	{
+		ID:  "https://api.weather.gov/stations/KBOI",
+		Geo: weather.Geometry{Type: "Point", Coordinates: []float64{-116.22278, 43.56444}},
+		Props: weather.Properties{
+			StationID: "KBOI",
+			Name:      "Boise Air Terminal",
+			TimeZone:  "America/Boise",
+			County:    "https://api.weather.gov/zones/county/IDC001",
+		},
+	},
+}
+
+func TestParseBoundingBox(t *testing.T) {
+
+	box, err := ParseBoundingBox("-123,37,-122,38")
+
+	if err != nil {
+		t.Fatalf("Failed parsing the bbox. %+v\n", err)
+	}
+
+	if !box.Contains(37.6, -122.4) || box.Contains(43.5, -116.2) {
+		t.Errorf("Bounding box contains not as expected. %+v\n", box)
+	}
+
+	for _, bad := range []string{"", "1,2,3", "a,b,c,d", "-123,38,-122,37"} {
+		if _, err := ParseBoundingBox(bad); err == nil {
+			t.Errorf("Expected an error for bbox %q", bad)
+		}
+	}
+}
+
+func TestSearchStations(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	store.InsertFeatures(context.Background(), "features", testFeatures)
+
+	box, _ := ParseBoundingBox("-123,37,-122,38")
+
+	cases := []struct {
+		filter   StationFilter
+		expected []string
+	}{
+		{StationFilter{}, []string{"KBOI", "KOAK", "KSFO"}},
+		{StationFilter{BBox: &box}, []string{"KOAK", "KSFO"}},
+		{StationFilter{State: "ca"}, []string{"KOAK", "KSFO"}},
+		{StationFilter{County: "CAC081"}, []string{"KSFO"}},
+		{StationFilter{TimeZone: "America/Boise"}, []string{"KBOI"}},
+		{StationFilter{Name: "international airport"}, []string{"KOAK", "KSFO"}},
+		{StationFilter{Name: "oakland airport"}, []string{"KOAK"}},
+		{StationFilter{State: "CA", Limit: 1}, []string{"KOAK"}},
+	}
+
+	for _, c := range cases {
+		page, err := store.SearchStations(context.Background(), "features", c.filter)
+
+		if err != nil {
+			t.Fatalf("Search failed. %+v\n", err)
+		}
+
+		features := page.Features
+
+		if len(features) != len(c.expected) {
+			t.Errorf("Filter %+v expected %v, got %d stations", c.filter, c.expected, len(features))
+			continue
+		}
+
+		for i, feature := range features {
+			if feature.StationID() != c.expected[i] {
+				t.Errorf("Filter %+v expected %v, got %s at %d", c.filter, c.expected, feature.StationID(), i)
+			}
+		}
+	}
+}
+
+func TestSearchStationsPaging(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	store.InsertFeatures(context.Background(), "features", testFeatures)
+
+	var ids []string
+
+	filter := StationFilter{State: "CA", Limit: 1}
+
+	for pages := 0; pages < 5; pages++ {
+		page, err := store.SearchStations(context.Background(), "features", filter)
+
+		if err != nil {
+			t.Fatalf("Search failed. %+v\n", err)
+		}
+
+		for _, feature := range page.Features {
+			ids = append(ids, feature.StationID())
+		}
+
+		if page.Next == "" {
+			break
+		}
+
+		filter.Cursor = page.Next
+	}
+
+	if len(ids) != 2 || ids[0] != "KOAK" || ids[1] != "KSFO" {
+		t.Errorf("Paged stations not as expected. %v\n", ids)
+	}
+
+	if _, err := store.SearchStations(context.Background(), "features", StationFilter{Cursor: "not a cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected an error for an invalid cursor")
+	}
+}
+
+func TestStationQueryCursor(t *testing.T) {
+
+	query, err := stationQuery(StationFilter{Cursor: encodeCursor([]interface{}{"KOAK", "KOAK"})})
+
+	if err != nil || len(query["sort"].([]interface{})) != 2 || query["search_after"] == nil {
+		t.Errorf("Expected the cursor after the station and its _id. %+v %+v\n", query, err)
+	}
+
+	// a cursor from before the _id tiebreaker
+	if _, err := stationQuery(StationFilter{Cursor: encodeCursor([]interface{}{"KOAK"})}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected an error for a cursor without a value for each sort. %+v\n", err)
+	}
+}
+
+func TestStationIterator(t *testing.T) {
+
+	store := NewMemoryStore()
+
+	store.InsertStationList(context.Background(), "stations", []string{
+		"https://api.weather.gov/stations/KSFO",
+		"https://api.weather.gov/stations/KCRG",
+		"https://api.weather.gov/stations/KBOI",
+	})
+
+	it := NewStationIterator(store, "stations", 2)
+
+	var stations []string
+
+	for it.Next() {
+		stations = append(stations, it.Station())
+	}
+
+	if it.Err() != nil || len(stations) != 3 || stations[2] != "https://api.weather.gov/stations/KSFO" {
+		t.Errorf("Iterated stations not as expected. %v %+v\n", stations, it.Err())
+	}
+
+	empty := NewStationIterator(store, "nothing", 2)
+
+	if empty.Next() || empty.Err() != nil {
+		t.Errorf("An empty index has no stations")
+	}
+}
@@ -0,0 +1,364 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+	bolt "go.etcd.io/bbolt"
+)
+
+// SummaryDateFormat the date of a daily summary, a UTC day
+const SummaryDateFormat = "2006-01-02"
+
+// DailySummary the observations of a station over a UTC day, rolled up. The measurements are in the
+// weather service's units, degrees Celsius, millimeters and km/h
+type DailySummary struct {
+	StationID               string           `json:"stationId"`
+	Date                    string           `json:"date"`
+	Observations            int              `json:"observations"`
+	First                   time.Time        `json:"first"`
+	Last                    time.Time        `json:"last"`
+	MinTemperature          weather.Quantity `json:"minTemperature"`
+	MaxTemperature          weather.Quantity `json:"maxTemperature"`
+	MeanTemperature         weather.Quantity `json:"meanTemperature"`
+	TotalPrecipitation      weather.Quantity `json:"totalPrecipitation"`
+	MaxWindGust             weather.Quantity `json:"maxWindGust"`
+	PrevailingWindDirection weather.Quantity `json:"prevailingWindDirection"`
+	PrevailingWindCompass   string           `json:"prevailingWindCompass,omitempty"`
+	UpdatedAt               time.Time        `json:"updatedAt"`
+}
+
+// Units the summary's measurements in the unit system
+func (s DailySummary) Units(system weather.UnitSystem) DailySummary {
+	for _, q := range []*weather.Quantity{&s.MinTemperature, &s.MaxTemperature, &s.MeanTemperature, &s.TotalPrecipitation, &s.MaxWindGust} {
+		*q = q.Units(system)
+	}
+
+	return s
+}
+
+// summaryID the station and date, so a recomputed summary replaces the last
+func summaryID(summary DailySummary) string {
+	return summary.StationID + "_" + summary.Date
+}
+
+// summaryBucket the bolt bucket of the daily summaries index
+func summaryBucket(index string) []byte {
+	return []byte("daily-summaries/" + index)
+}
+
+// StationObservations the observations of the station from the daily Elastic indices read through the
+// alias, oldest first. from is inclusive and to exclusive
+func (s *ElasticStore) StationObservations(ctx context.Context, index string, stationID string, from time.Time, to time.Time) ([]weather.Observation, error) {
+
+	var buf bytes.Buffer
+
+	err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{"stationId": stationID}},
+					map[string]interface{}{"range": map[string]interface{}{
+						"timestamp": map[string]interface{}{
+							"gte": from.UTC().Format(time.RFC3339),
+							"lt":  to.UTC().Format(time.RFC3339),
+						},
+					}},
+				},
+			},
+		},
+		"sort": []interface{}{map[string]interface{}{"timestamp": "asc"}},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(&buf),
+		s.client.Search.WithSize(10000),
+		s.client.Search.WithIgnoreUnavailable(true),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("[%s] searching observations failed", res.Status())
+	}
+
+	var r struct {
+		Hits struct {
+			Hits []struct {
+				Source observationDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	observations := make([]weather.Observation, 0, len(r.Hits.Hits))
+
+	for _, hit := range r.Hits.Hits {
+		observations = append(observations, hit.Source.Observation)
+	}
+
+	return observations, nil
+}
+
+// InsertDailySummaries into the Elastic index
+func (s *ElasticStore) InsertDailySummaries(ctx context.Context, index string, summaries []DailySummary) (BulkResult, error) {
+
+	documents := make([]bulkDocument, 0, len(summaries))
+
+	for _, summary := range summaries {
+
+		b, err := json.Marshal(summary)
+
+		if err != nil {
+			return BulkResult{}, fmt.Errorf("Unable to marshall summary %s: %s", summaryID(summary), err)
+		}
+
+		documents = append(documents, bulkDocument{ID: summaryID(summary), Body: b})
+	}
+
+	return s.bulkIndex(ctx, index, documents)
+}
+
+// DailySummaries the summaries of the station in the Elastic index from and to the dates, inclusive, by date
+func (s *ElasticStore) DailySummaries(ctx context.Context, index string, stationID string, from string, to string) ([]DailySummary, error) {
+
+	var buf bytes.Buffer
+
+	err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{"stationId": stationID}},
+					map[string]interface{}{"range": map[string]interface{}{
+						"date": map[string]interface{}{"gte": from, "lte": to},
+					}},
+				},
+			},
+		},
+		"sort": []interface{}{map[string]interface{}{"date": "asc"}},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(&buf),
+		s.client.Search.WithSize(10000),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	// nothing has been summarized yet
+	if res.StatusCode == 404 {
+		return []DailySummary{}, nil
+	}
+
+	if res.IsError() {
+		return nil, fmt.Errorf("[%s] searching daily summaries failed", res.Status())
+	}
+
+	var r struct {
+		Hits struct {
+			Hits []struct {
+				Source DailySummary `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]DailySummary, 0, len(r.Hits.Hits))
+
+	for _, hit := range r.Hits.Hits {
+		summaries = append(summaries, hit.Source)
+	}
+
+	return summaries, nil
+}
+
+// sortSummaries order the summaries by date
+func sortSummaries(summaries []DailySummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Date < summaries[j].Date
+	})
+}
+
+// sortObservations order the observations by time
+func sortObservations(observations []weather.Observation) {
+	sort.Slice(observations, func(i, j int) bool {
+		return observations[i].Props.Timestamp.Before(observations[j].Props.Timestamp)
+	})
+}
+
+// StationObservations the observations of the station in the index, oldest first. from is inclusive
+// and to exclusive
+func (s *MemoryStore) StationObservations(ctx context.Context, index string, stationID string, from time.Time, to time.Time) ([]weather.Observation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	observations := make([]weather.Observation, 0)
+
+	for _, doc := range s.observations[index] {
+		if doc.StationID == stationID && !doc.Timestamp.Before(from) && doc.Timestamp.Before(to) {
+			observations = append(observations, doc.Observation)
+		}
+	}
+
+	sortObservations(observations)
+
+	return observations, nil
+}
+
+// InsertDailySummaries insert the summaries into the index
+func (s *MemoryStore) InsertDailySummaries(ctx context.Context, index string, summaries []DailySummary) (BulkResult, error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.summaries[index] == nil {
+		s.summaries[index] = make(map[string]DailySummary)
+	}
+
+	for _, summary := range summaries {
+		s.summaries[index][summaryID(summary)] = summary
+	}
+
+	return BulkResult{Indexed: uint64(len(summaries)), Duration: time.Since(start)}, nil
+}
+
+// DailySummaries the summaries of the station in the index from and to the dates, inclusive, by date
+func (s *MemoryStore) DailySummaries(ctx context.Context, index string, stationID string, from string, to string) ([]DailySummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make([]DailySummary, 0)
+
+	for _, summary := range s.summaries[index] {
+		if summary.StationID == stationID && summary.Date >= from && summary.Date <= to {
+			summaries = append(summaries, summary)
+		}
+	}
+
+	sortSummaries(summaries)
+
+	return summaries, nil
+}
+
+// StationObservations the observations of the station in the index, oldest first. from is inclusive and
+// to exclusive. The keys are the station and the time, so only the station's keys are read
+func (s *BoltStore) StationObservations(ctx context.Context, index string, stationID string, from time.Time, to time.Time) ([]weather.Observation, error) {
+
+	observations := make([]weather.Observation, 0)
+
+	first := []byte(stationID + "_" + from.UTC().Format(time.RFC3339))
+	last := []byte(stationID + "_" + to.UTC().Format(time.RFC3339))
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(observationsBucket(index))
+
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+
+		for k, v := c.Seek(first); k != nil && bytes.Compare(k, last) < 0; k, v = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			var doc observationDocument
+
+			if err := json.Unmarshal(v, &doc); err != nil {
+				return err
+			}
+
+			observations = append(observations, doc.Observation)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return observations, nil
+}
+
+// InsertDailySummaries insert the summaries into the index
+func (s *BoltStore) InsertDailySummaries(ctx context.Context, index string, summaries []DailySummary) (BulkResult, error) {
+
+	values := make(map[string]interface{}, len(summaries))
+
+	for _, summary := range summaries {
+		values[summaryID(summary)] = summary
+	}
+
+	return s.put(summaryBucket(index), values)
+}
+
+// DailySummaries the summaries of the station in the index from and to the dates, inclusive, by date
+func (s *BoltStore) DailySummaries(ctx context.Context, index string, stationID string, from string, to string) ([]DailySummary, error) {
+
+	summaries := make([]DailySummary, 0)
+
+	first := []byte(stationID + "_" + from)
+	last := []byte(stationID + "_" + to)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(summaryBucket(index))
+
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+
+		for k, v := c.Seek(first); k != nil && bytes.Compare(k, last) <= 0; k, v = c.Next() {
+			var summary DailySummary
+
+			if err := json.Unmarshal(v, &summary); err != nil {
+				return err
+			}
+
+			summaries = append(summaries, summary)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+func testSummaries(t *testing.T, store Store) {
+
+	ctx := context.Background()
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	observations := make([]weather.Observation, 0)
+
+	for _, station := range []string{"KSFO", "KSF", "KSFOX"} {
+		for hour := -1; hour <= 24; hour++ {
+			observation := weather.Observation{}
+			observation.Props.Station = "https://api.weather.gov/stations/" + station
+			observation.Props.Timestamp = day.Add(time.Duration(hour) * time.Hour)
+			observations = append(observations, observation)
+		}
+	}
+
+	store.InsertObservations(ctx, DefaultIndices.Observations, observations)
+
+	found, err := store.StationObservations(ctx, DefaultIndices.Observations, "KSFO", day, day.AddDate(0, 0, 1))
+
+	if err != nil || len(found) != 24 {
+		t.Fatalf("Expected the 24 observations of the day. %d %+v\n", len(found), err)
+	}
+
+	if !found[0].Props.Timestamp.Equal(day) || found[0].StationID() != "KSFO" {
+		t.Errorf("Expected the first observation at midnight. %+v\n", found[0].Props)
+	}
+
+	summaries := []DailySummary{
+		{StationID: "KSFO", Date: "2026-10-19", Observations: 2},
+		{StationID: "KSFO", Date: "2026-10-17", Observations: 1},
+		{StationID: "KSFO", Date: "2026-10-18", Observations: 1},
+		{StationID: "KSF", Date: "2026-10-18", Observations: 1},
+	}
+
+	store.InsertDailySummaries(ctx, DefaultIndices.DailySummaries, summaries)
+
+	// recomputing a day replaces it
+	store.InsertDailySummaries(ctx, DefaultIndices.DailySummaries, []DailySummary{{StationID: "KSFO", Date: "2026-10-18", Observations: 24}})
+
+	if count, err := store.IndexCount(ctx, DefaultIndices.DailySummaries); err != nil || count != 4 {
+		t.Errorf("Expected the 4 summaries counted. %d %+v\n", count, err)
+	}
+
+	stored, err := store.DailySummaries(ctx, DefaultIndices.DailySummaries, "KSFO", "2026-10-18", "2026-10-19")
+
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("Expected 2 summaries. %+v %+v\n", stored, err)
+	}
+
+	if stored[0].Date != "2026-10-18" || stored[0].Observations != 24 || stored[1].Date != "2026-10-19" {
+		t.Errorf("Summaries not as expected. %+v\n", stored)
+	}
+}
+
+func TestMemorySummaries(t *testing.T) {
+	testSummaries(t, NewMemoryStore())
+}
+
+func TestBoltSummaries(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-weather")
+
+	if err != nil {
+		t.Fatalf("Failed creating temp dir. %+v\n", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	store, err := NewBoltStore(filepath.Join(dir, "go-weather.db"))
+
+	if err != nil {
+		t.Fatalf("Failed opening the bolt store. %+v\n", err)
+	}
+
+	defer store.Close()
+
+	testSummaries(t, store)
+}
@@ -0,0 +1,397 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// templateVersion bump when a mapping changes so running servers install the new templates
+const templateVersion = 6
+
+// Indices the index names the templates apply to. Observations is the prefix of the daily
+// observation indices and the alias they are read through
+type Indices struct {
+	Stations       string
+	Features       string
+	Alerts         string
+	Observations   string
+	DailySummaries string
+}
+
+// DefaultIndices the index names used when none are configured
+var DefaultIndices = Indices{
+	Stations:       "stations",
+	Features:       "features",
+	Alerts:         "alerts",
+	Observations:   "observations",
+	DailySummaries: "daily-summaries",
+}
+
+// GeoPoint an Elastic geo_point
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+var keyword = map[string]interface{}{"type": "keyword"}
+var double = map[string]interface{}{"type": "double"}
+var date = map[string]interface{}{"type": "date"}
+var geoPoint = map[string]interface{}{"type": "geo_point"}
+
+// text full text with a keyword sub field for sorting and exact matches
+var text = map[string]interface{}{
+	"type": "text",
+	"fields": map[string]interface{}{
+		"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+	},
+}
+
+// lowercaseKeyword a keyword matched without regard to case
+var lowercaseKeyword = map[string]interface{}{"type": "keyword", "normalizer": "lowercase"}
+
+func properties(fields map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"properties": fields}
+}
+
+// quantity the mapping of a weather service value and unit
+var quantity = properties(map[string]interface{}{
+	"value":          double,
+	"unitCode":       keyword,
+	"qualityControl": keyword,
+})
+
+// indexTemplate a composable index template for the index pattern
+func indexTemplate(pattern string, mappings map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"index_patterns": []string{pattern},
+		"version":        templateVersion,
+		"priority":       100,
+		"_meta":          map[string]interface{}{"managed_by": "go-weather"},
+		"template": map[string]interface{}{
+			"settings": map[string]interface{}{
+				"analysis": map[string]interface{}{
+					"normalizer": map[string]interface{}{
+						"lowercase": map[string]interface{}{"type": "custom", "filter": []string{"lowercase"}},
+					},
+				},
+			},
+			"mappings": mappings,
+		},
+	}
+}
+
+// withAlias every index the template creates joins the alias
+func withAlias(alias string, template map[string]interface{}) map[string]interface{} {
+	template["template"].(map[string]interface{})["aliases"] = map[string]interface{}{
+		alias: map[string]interface{}{},
+	}
+
+	return template
+}
+
+// templates the go-weather index templates by name
+func templates(indices Indices) map[string]map[string]interface{} {
+
+	return map[string]map[string]interface{}{
+		"go-weather-stations": indexTemplate(indices.Stations, properties(map[string]interface{}{
+			"station": keyword,
+		})),
+
+		"go-weather-features": indexTemplate(indices.Features, properties(map[string]interface{}{
+			"stationId": keyword,
+			"state":     keyword,
+			"county":    keyword,
+			"location":  geoPoint,
+			"elevation": double,
+			"cachedAt":  date,
+			"feature": properties(map[string]interface{}{
+				"id":   keyword,
+				"type": keyword,
+				"geometry": properties(map[string]interface{}{
+					"type":        keyword,
+					"coordinates": double,
+				}),
+				"properties": properties(map[string]interface{}{
+					"@id":               keyword,
+					"@type":             keyword,
+					"elevation":         quantity,
+					"stationIdentifier": keyword,
+					"name":              text,
+					"timeZone":          keyword,
+					"forecast":          keyword,
+					"county":            keyword,
+					"fireWeatherZone":   keyword,
+				}),
+			}),
+		})),
+
+		"go-weather-alerts": indexTemplate(indices.Alerts, properties(map[string]interface{}{
+			"states": keyword,
+			"alert": properties(map[string]interface{}{
+				"id":   keyword,
+				"type": keyword,
+				// a malformed shape is rejected and reported as a bulk failure, not left out of point queries
+				"geometry": map[string]interface{}{"type": "geo_shape"},
+				"properties": properties(map[string]interface{}{
+					"id":            keyword,
+					"areaDesc":      text,
+					"affectedZones": keyword,
+					"sent":          date,
+					"effective":     date,
+					"onset":         date,
+					"expires":       date,
+					"ends":          date,
+					"status":        keyword,
+					"messageType":   keyword,
+					"category":      keyword,
+					"severity":      lowercaseKeyword,
+					"certainty":     lowercaseKeyword,
+					"urgency":       lowercaseKeyword,
+					"event":         text,
+					"headline":      text,
+					"description":   text,
+					"instruction":   text,
+				}),
+			}),
+		})),
+
+		"go-weather-observations": withAlias(indices.Observations, indexTemplate(indices.Observations+"-*", properties(map[string]interface{}{
+			"stationId": keyword,
+			"timestamp": date,
+			"location":  geoPoint,
+			"derived": properties(map[string]interface{}{
+				"heatIndex":           double,
+				"windChill":           double,
+				"apparentTemperature": double,
+				"relativeHumidity":    double,
+				"dewpointDepression":  double,
+				"ceiling":             double,
+				"flightCategory":      keyword,
+			}),
+			"observation": properties(map[string]interface{}{
+				"id": keyword,
+				"properties": properties(map[string]interface{}{
+					"@id":                keyword,
+					"station":            keyword,
+					"timestamp":          date,
+					"rawMessage":         text,
+					"textDescription":    text,
+					"temperature":        quantity,
+					"dewpoint":           quantity,
+					"windDirection":      quantity,
+					"windSpeed":          quantity,
+					"windGust":           quantity,
+					"barometricPressure": quantity,
+					"seaLevelPressure":   quantity,
+					"visibility":         quantity,
+				}),
+			}),
+		}))),
+
+		"go-weather-daily-summaries": indexTemplate(indices.DailySummaries, properties(map[string]interface{}{
+			"stationId":               keyword,
+			"date":                    map[string]interface{}{"type": "date", "format": "yyyy-MM-dd"},
+			"observations":            map[string]interface{}{"type": "integer"},
+			"first":                   date,
+			"last":                    date,
+			"minTemperature":          quantity,
+			"maxTemperature":          quantity,
+			"meanTemperature":         quantity,
+			"totalPrecipitation":      quantity,
+			"maxWindGust":             quantity,
+			"prevailingWindDirection": quantity,
+			"prevailingWindCompass":   keyword,
+			"updatedAt":               date,
+		})),
+	}
+}
+
+// installedTemplateVersion the version of the installed template, 0 when it is not installed
+func (s *ElasticStore) installedTemplateVersion(ctx context.Context, name string) (int, error) {
+
+	res, err := s.client.Indices.GetIndexTemplate(
+		s.client.Indices.GetIndexTemplate.WithContext(ctx),
+		s.client.Indices.GetIndexTemplate.WithName(name),
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+
+	if res.IsError() {
+		return 0, fmt.Errorf("[%s] getting index template %s failed", res.Status(), name)
+	}
+
+	var r struct {
+		IndexTemplates []struct {
+			IndexTemplate struct {
+				Version int `json:"version"`
+			} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return 0, err
+	}
+
+	if len(r.IndexTemplates) == 0 {
+		return 0, nil
+	}
+
+	return r.IndexTemplates[0].IndexTemplate.Version, nil
+}
+
+// InstallTemplates put the index templates unless the same or a newer version is installed.
+// Templates only apply to indices created afterwards, existing indices must be reindexed. See
+// CheckMappings
+func (s *ElasticStore) InstallTemplates(ctx context.Context, indices Indices) error {
+
+	for name, template := range templates(indices) {
+
+		version, err := s.installedTemplateVersion(ctx, name)
+
+		if err != nil {
+			return err
+		}
+
+		if version >= templateVersion {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		if err := json.NewEncoder(&buf).Encode(template); err != nil {
+			return fmt.Errorf("Error encoding index template %s: %s", name, err)
+		}
+
+		res, err := s.client.Indices.PutIndexTemplate(name, &buf,
+			s.client.Indices.PutIndexTemplate.WithContext(ctx))
+
+		if err != nil {
+			return err
+		}
+
+		res.Body.Close()
+
+		if res.IsError() {
+			return fmt.Errorf("[%s] putting index template %s failed", res.Status(), name)
+		}
+
+		log.Printf("Installed index template %s version %d", name, templateVersion)
+	}
+
+	return nil
+}
+
+// mappedTypes the type of each field in the mapping by its dotted path. Objects have no type of their own
+func mappedTypes(mapping map[string]interface{}, prefix string, types map[string]string) {
+
+	fields, _ := mapping["properties"].(map[string]interface{})
+
+	for name, field := range fields {
+
+		f, ok := field.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		if kind, ok := f["type"].(string); ok {
+			types[prefix+name] = kind
+		}
+
+		mappedTypes(f, prefix+name+".", types)
+	}
+}
+
+// mappingProblems the fields of the index mapping that are not mapped as the template maps them
+func mappingProblems(template map[string]interface{}, mapping map[string]interface{}) []string {
+
+	want := make(map[string]string)
+	got := make(map[string]string)
+
+	mappedTypes(template["template"].(map[string]interface{})["mappings"].(map[string]interface{}), "", want)
+	mappedTypes(mapping, "", got)
+
+	var problems []string
+
+	for field, kind := range want {
+		switch actual := got[field]; actual {
+		case kind:
+		case "":
+			problems = append(problems, fmt.Sprintf("%s is not mapped, expected %s", field, kind))
+		default:
+			problems = append(problems, fmt.Sprintf("%s is %s, expected %s", field, actual, kind))
+		}
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
+
+// CheckMappings the indices that already exist are mapped as the templates map them. An index created
+// before the templates were installed has dynamic mappings, its locations are not geo points and its
+// ids are text, so the geo, filter and paging queries fail on it. Such an index must be reindexed into
+// one created from the template, the error names the index and the fields that differ
+func (s *ElasticStore) CheckMappings(ctx context.Context, indices Indices) error {
+
+	for name, template := range templates(indices) {
+
+		pattern := template["index_patterns"].([]string)[0]
+
+		res, err := s.client.Indices.GetMapping(
+			s.client.Indices.GetMapping.WithContext(ctx),
+			s.client.Indices.GetMapping.WithIndex(pattern),
+			s.client.Indices.GetMapping.WithAllowNoIndices(true),
+			s.client.Indices.GetMapping.WithIgnoreUnavailable(true),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		var r map[string]struct {
+			Mappings map[string]interface{} `json:"mappings"`
+		}
+
+		if res.StatusCode == http.StatusNotFound {
+			res.Body.Close()
+			continue
+		}
+
+		if res.IsError() {
+			res.Body.Close()
+			return fmt.Errorf("[%s] getting the mapping of %s failed", res.Status(), pattern)
+		}
+
+		err = json.NewDecoder(res.Body).Decode(&r)
+		res.Body.Close()
+
+		if err != nil {
+			return err
+		}
+
+		for index, mapping := range r {
+			if problems := mappingProblems(template, mapping.Mappings); len(problems) > 0 {
+				return fmt.Errorf("Index %s is not mapped by the %s template version %d, reindex it into an index "+
+					"created from the template or delete it and load it again: %s", index, name, templateVersion,
+					strings.Join(problems, "; "))
+			}
+		}
+	}
+
+	return nil
+}
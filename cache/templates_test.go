@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+func TestTemplates(t *testing.T) {
+
+	all := templates(DefaultIndices)
+
+	for _, name := range []string{"go-weather-stations", "go-weather-features", "go-weather-alerts", "go-weather-observations", "go-weather-daily-summaries"} {
+		if _, ok := all[name]; !ok {
+			t.Errorf("Missing index template %s", name)
+		}
+	}
+
+	b, err := json.Marshal(all["go-weather-features"])
+
+	if err != nil {
+		t.Fatalf("Failed marshalling the features template. %+v\n", err)
+	}
+
+	features := string(b)
+
+	if !strings.Contains(features, `"location":{"type":"geo_point"}`) ||
+		!strings.Contains(features, `"stationId":{"type":"keyword"}`) ||
+		!strings.Contains(features, `"index_patterns":["features"]`) {
+		t.Errorf("Features template not as expected. %s\n", features)
+	}
+
+	b, _ = json.Marshal(all["go-weather-alerts"])
+
+	if !strings.Contains(string(b), `"geometry":{"type":"geo_shape"}`) {
+		t.Errorf("Alert shapes should be geo_shape and never silently ignored. %s\n", b)
+	}
+
+	b, _ = json.Marshal(all["go-weather-observations"])
+
+	if !strings.Contains(string(b), `"index_patterns":["observations-*"]`) ||
+		!strings.Contains(string(b), `"aliases":{"observations":{}}`) {
+		t.Errorf("Observations template pattern and alias not as expected. %s\n", b)
+	}
+
+	if index := ObservationIndex("observations", time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("EDT", -4*3600))); index != "observations-2026.10.19" {
+		t.Errorf("Daily index not in UTC. %s\n", index)
+	}
+}
+
+func TestMappingProblems(t *testing.T) {
+
+	template := templates(DefaultIndices)["go-weather-stations"]
+
+	created := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"station": map[string]interface{}{"type": "keyword"},
+		},
+	}
+
+	if problems := mappingProblems(template, created); len(problems) != 0 {
+		t.Errorf("An index created from the template should match it. %v\n", problems)
+	}
+
+	// a baseline index mapped dynamically
+	dynamic := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"station": map[string]interface{}{"type": "text", "fields": map[string]interface{}{
+				"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+			}},
+		},
+	}
+
+	if problems := mappingProblems(template, dynamic); len(problems) != 1 || problems[0] != "station is text, expected keyword" {
+		t.Errorf("Expected the dynamic station field reported. %v\n", problems)
+	}
+
+	features := templates(DefaultIndices)["go-weather-features"]
+
+	if problems := mappingProblems(features, map[string]interface{}{}); len(problems) == 0 {
+		t.Errorf("An unmapped features index should be reported")
+	}
+}
+
+func TestFeatureDocument(t *testing.T) {
+
+	feature, err := weather.ParseWeather(`{
+		"id": "https://api.weather.gov/stations/KSFO",
+		"geometry": {"type": "Point", "coordinates": [-122.36558, 37.61961]},
+		"properties": {"elevation": {"value": 3.048, "unitCode": "unit:m"}}
+	}`)
+
+	if err != nil {
+		t.Fatalf("Failed parsing the feature. %+v\n", err)
+	}
+
+	doc := newFeatureDocument(feature, time.Now())
+
+	if doc.StationID != "KSFO" {
+		t.Errorf("Station ID not as expected. %s\n", doc.StationID)
+	}
+
+	if doc.Location == nil || doc.Location.Lat != 37.61961 || doc.Location.Lon != -122.36558 {
+		t.Errorf("Location not as expected. %+v\n", doc.Location)
+	}
+
+	if doc.Elevation == nil || *doc.Elevation != 3.048 {
+		t.Errorf("Elevation not as expected. %+v\n", doc.Elevation)
+	}
+
+	if doc := newFeatureDocument(weather.Feature{ID: "x/KNOP"}, time.Now()); doc.Location != nil || doc.Elevation != nil {
+		t.Errorf("A feature without geometry has no location. %+v\n", doc)
+	}
+}
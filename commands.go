@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/cache"
+	"github.com/EdSwArchitect/go-weather/config"
+	"github.com/EdSwArchitect/go-weather/jobs"
+	"github.com/EdSwArchitect/go-weather/weather"
+	"github.com/gorilla/mux"
+)
+
+// shutdownTimeout how long the server waits for requests in flight when stopping
+const shutdownTimeout = 30 * time.Second
+
+func usage() {
+	out := flag.CommandLine.Output()
+
+	fmt.Fprintf(out, "Usage: %s [flags] [command]\n\n", os.Args[0])
+	fmt.Fprintf(out, "Commands:\n")
+	fmt.Fprintf(out, "  serve       serve the API, the default\n")
+	fmt.Fprintf(out, "  ingest      run the scheduled loads\n")
+	fmt.Fprintf(out, "  once <job>  run one load and exit. The jobs are %v\n\n", jobKinds())
+	fmt.Fprintf(out, "Flags:\n")
+
+	flag.PrintDefaults()
+}
+
+// jobKinds the names of the load jobs, sorted
+func jobKinds() []string {
+	kinds := make([]string, 0, len(loadJobs))
+
+	for kind := range loadJobs {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+func defineFlags() {
+	flag.StringVar(&espUri, "espUri", "localhost:9200", "The ESP host and port number")
+	flag.IntVar(&httpPort, "serverPort", 8080, "The HTTP server port")
+	flag.StringVar(&configFile, "configFile", "", "The configuration file")
+	flag.StringVar(&storeKind, "store", cache.StoreElastic, "The store: elastic, memory or bolt")
+	flag.StringVar(&dataPath, "dataPath", cache.DefaultBoltPath, "The bolt store database file")
+	flag.DurationVar(&requestTimeout, "requestTimeout", 0, "How long a request may run, zero is no limit")
+	flag.BoolVar(&runSchedules, "schedule", false, "Also run the scheduled loads when serving")
+}
+
+// configure apply the configuration file, when there is one, over the flags
+func configure() error {
+
+	if configFile != "" {
+		log.Printf("Working with log file: %s", configFile)
+
+		config, err := config.ReadConfig(&configFile)
+
+		if err != nil {
+			return err
+		}
+
+		espUri = config.EspURI
+		if config.FeaturesURI != "" {
+			featuresURI = config.FeaturesURI
+		}
+
+		if config.StationsURI != "" {
+			stationsURI = config.StationsURI
+		}
+
+		if config.FeatureTTL != 0 {
+			featureTTL = time.Duration(config.FeatureTTL) * time.Second
+		}
+		httpPort = config.ServerPort
+
+		if config.Store != "" {
+			storeKind = config.Store
+		}
+
+		if config.DataPath != "" {
+			dataPath = config.DataPath
+		}
+
+		if config.AlertsURI != "" {
+			alertsURI = config.AlertsURI
+		}
+
+		if config.RequestTimeout != 0 {
+			requestTimeout = time.Duration(config.RequestTimeout) * time.Second
+		}
+
+		if config.ObservationsIndex != "" {
+			observationsURI = config.ObservationsIndex
+		}
+
+		if config.SummariesIndex != "" {
+			summariesURI = config.SummariesIndex
+		}
+
+		watchlist = config.Watchlist
+		schedules = config.Schedules
+
+		retention = cache.Retention{
+			Observations:   time.Duration(config.Retention.Observations) * 24 * time.Hour,
+			Alerts:         time.Duration(config.Retention.Alerts) * 24 * time.Hour,
+			DailySummaries: time.Duration(config.Retention.DailySummaries) * 24 * time.Hour,
+		}
+
+		weather.DefaultClient = weather.NewClient(weather.ClientConfig{
+			BaseURL:   config.WeatherURI,
+			UserAgent: config.UserAgent,
+			Timeout:   time.Duration(config.WeatherTimeout) * time.Second,
+			MaxPages:  config.MaxPages,
+		})
+	}
+
+	log.Printf("espURI: %s", espUri)
+	log.Printf("configFile: %s", configFile)
+	log.Printf("featuresURI: %s", featuresURI)
+	log.Printf("stationsURI: %s", stationsURI)
+	log.Printf("featureTTL: %s", featureTTL)
+	log.Printf("alertsURI: %s", alertsURI)
+	log.Printf("observationsURI: %s", observationsURI)
+	log.Printf("summariesURI: %s", summariesURI)
+	log.Printf("watchlist: %v", watchlist)
+	log.Printf("retention: observations %s, alerts %s", retention.Observations, retention.Alerts)
+	log.Printf("httpPort: %d", httpPort)
+	log.Printf("requestTimeout: %s", requestTimeout)
+	log.Printf("store: %s", storeKind)
+	log.Printf("dataPath: %s", dataPath)
+	log.Printf("weatherURI: %s", weather.DefaultClient.BaseURL())
+
+	return nil
+}
+
+// storeIndices the configured index names
+func storeIndices() cache.Indices {
+	return cache.Indices{
+		Stations:       stationsURI,
+		Features:       featuresURI,
+		Alerts:         alertsURI,
+		Observations:   observationsURI,
+		DailySummaries: summariesURI,
+	}
+}
+
+// openStore connect to the configured store
+func openStore() error {
+
+	var err error
+
+	store, err = cache.NewStore(cache.StoreConfig{
+		Kind:    storeKind,
+		Host:    espUri,
+		Path:    dataPath,
+		Indices: storeIndices(),
+	})
+
+	return err
+}
+
+// closeStore close the store, when it holds a file open
+func closeStore() {
+	if closer, ok := store.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Closing the store failed: %s", err)
+		}
+	}
+}
+
+// signalled a context that is done on an interrupt or terminate signal
+func signalled() (context.Context, context.CancelFunc) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		select {
+		case sig := <-signals:
+			log.Printf("Received %s, stopping", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// listen serve the handler until the context is done, then wait for the requests in flight
+func listen(ctx context.Context, handler http.Handler) error {
+
+	server := &http.Server{Addr: fmt.Sprintf(":%d", httpPort), Handler: handler}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return server.Shutdown(shutdown)
+}
+
+// statusRoutes the job, schedule and index status, served in every mode. Retention deletes data so it
+// only answers POST
+func statusRoutes(router *mux.Router) {
+	router.HandleFunc("/", heartBeat)
+	router.HandleFunc("/jobs", getJobs)
+	router.HandleFunc("/jobs/{jobId}", getJob)
+	router.HandleFunc("/schedules", getSchedules)
+	router.HandleFunc("/admin/indices", getIndexStats)
+	router.HandleFunc("/admin/retention", applyRetention).Methods(http.MethodPost)
+}
+
+func apiRouter() *mux.Router {
+
+	router := mux.NewRouter()
+
+	router.Use(withTimeout, withUnits)
+
+	statusRoutes(router)
+
+	router.HandleFunc("/stations", getStations)
+	router.HandleFunc("/stations/near", getNearStations)
+	router.HandleFunc("/features", getFeatures)
+	router.HandleFunc("/loadStations", loadStations)
+	router.HandleFunc("/station/{stationId}", getStation)
+	router.HandleFunc("/station/{stationId}/observations", getObservations)
+	router.HandleFunc("/station/{stationId}/observations/latest", getLatestObservation)
+	router.HandleFunc("/station/{stationId}/forecast", getStationForecast)
+	router.HandleFunc("/station/{stationId}/taf", getStationTAF)
+	router.HandleFunc("/station/{stationId}/daily", getDailySummaries)
+	router.HandleFunc("/forecast", getForecast)
+	router.HandleFunc("/forecast/hourly", getHourlyForecast)
+	router.HandleFunc("/alerts", getAlerts)
+	router.HandleFunc("/loadAlerts", loadAlerts)
+	router.HandleFunc("/loadFeatures", loadFeatures)
+	router.HandleFunc("/loadObservations", loadObservations)
+	router.HandleFunc("/feature/{stationId}", getStation)
+	router.HandleFunc("/writeStatic/{saticID}", writeStatic)
+
+	return router
+}
+
+// startScheduler schedule the configured loads
+func startScheduler(ctx context.Context) error {
+
+	var err error
+
+	scheduler, err = newScheduler(schedules)
+
+	if err != nil {
+		return fmt.Errorf("Schedules failed: %s", err)
+	}
+
+	scheduler.Start(ctx)
+
+	return nil
+}
+
+// serve the API, and the scheduled loads when asked to
+func serve(ctx context.Context) error {
+
+	if runSchedules {
+		if err := startScheduler(ctx); err != nil {
+			return err
+		}
+
+		defer scheduler.Stop()
+	}
+
+	return listen(ctx, apiRouter())
+}
+
+// ingest run the scheduled loads, serving only the job and schedule status
+func ingest(ctx context.Context) error {
+
+	if len(schedules) == 0 {
+		return fmt.Errorf("No schedules configured to ingest")
+	}
+
+	if err := startScheduler(ctx); err != nil {
+		return err
+	}
+
+	defer scheduler.Stop()
+
+	router := mux.NewRouter()
+
+	router.Use(withUnits)
+
+	statusRoutes(router)
+
+	return listen(ctx, router)
+}
+
+// once run the named load, writing the finished job to stdout
+func once(ctx context.Context, args []string) error {
+
+	if len(args) != 1 {
+		return fmt.Errorf("once takes one job, one of %v", jobKinds())
+	}
+
+	fn, ok := loadJobs[args[0]]
+
+	if !ok {
+		return fmt.Errorf("Unknown job %q, expected one of %v", args[0], jobKinds())
+	}
+
+	job := jobManager.Submit(args[0], fn)
+
+	job, err := jobManager.Wait(ctx, job.ID)
+
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(job, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", b)
+
+	if job.State != jobs.Succeeded {
+		return fmt.Errorf("Job %s %s", job.Kind, job.State)
+	}
+
+	return nil
+}
+
+func main() {
+
+	defineFlags()
+
+	flag.Usage = usage
+	flag.Parse()
+
+	command, args := "serve", flag.Args()
+
+	if len(args) > 0 {
+		command = args[0]
+
+		// the flags may also follow the command
+		flag.CommandLine.Parse(args[1:])
+		args = flag.Args()
+	}
+
+	switch command {
+	case "serve", "ingest", "once":
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := configure(); err != nil {
+		log.Fatalf("Configuration failed: %s", err)
+	}
+
+	if err := openStore(); err != nil {
+		log.Fatalf("Store failed: %s", err)
+	}
+
+	ctx, cancel := signalled()
+
+	var err error
+
+	switch command {
+	case "serve":
+		err = serve(ctx)
+	case "ingest":
+		err = ingest(ctx)
+	case "once":
+		err = once(ctx, args)
+	}
+
+	cancel()
+	jobManager.Close()
+	closeStore()
+
+	if err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
@@ -12,7 +12,30 @@ import (
 	"espUri" : "localhost:9200",
 	"stationsIndex" : "stations",
 	"serverPort" : 80,
-	"featuresIndex"	 : "features"
+	"featuresIndex"	 : "features",
+	"alertsIndex" : "alerts",
+	"store" : "elastic",
+	"dataPath" : "/data/go-weather.db",
+	"featureTTL" : 86400,
+	"weatherUri" : "https://api.weather.gov",
+	"userAgent" : "(go-weather, someone@example.com)",
+	"weatherTimeout" : 30,
+	"maxPages" : 200,
+	"requestTimeout" : 300,
+	"observationsIndex" : "observations",
+	"dailySummariesIndex" : "daily-summaries",
+	"watchlist" : ["KSFO", "KCRG"],
+	"schedules" : [
+		{"job" : "stations", "cron" : "@daily", "jitter" : 600},
+		{"job" : "features", "cron" : "30 3 * * *", "jitter" : 600},
+		{"job" : "observations", "every" : 600, "jitter" : 60},
+		{"job" : "alerts", "every" : 300, "jitter" : 30},
+		{"job" : "retention", "cron" : "15 0 * * *"}
+	],
+	"retention" : {
+		"observations" : 30,
+		"alerts" : 7
+	}
 }
 */
 
@@ -21,6 +44,43 @@ type Config struct {
 	StationsURI string `json:"stationsIndex"`
 	ServerPort  int    `json:"serverPort"`
 	FeaturesURI string `json:"featuresIndex"`
+	AlertsURI   string `json:"alertsIndex"`
+	// Store where the data is kept: elastic, memory or bolt
+	Store string `json:"store"`
+	// DataPath the bolt store database file
+	DataPath string `json:"dataPath"`
+	// FeatureTTL seconds a cached feature is fresh, negative never expires
+	FeatureTTL int    `json:"featureTTL"`
+	WeatherURI string `json:"weatherUri"`
+	UserAgent  string `json:"userAgent"`
+	// WeatherTimeout the weather service request timeout in seconds
+	WeatherTimeout int `json:"weatherTimeout"`
+	// MaxPages the most pages of stations walked when loading
+	MaxPages int `json:"maxPages"`
+	// RequestTimeout seconds a request may run, zero is no limit
+	RequestTimeout    int    `json:"requestTimeout"`
+	ObservationsIndex string `json:"observationsIndex"`
+	SummariesIndex    string `json:"dailySummariesIndex"`
+	// Watchlist the stations whose latest observations are loaded
+	Watchlist []string   `json:"watchlist"`
+	Schedules []Schedule `json:"schedules"`
+	Retention Retention  `json:"retention"`
+}
+
+// Retention the days each kind of time series data is kept. Zero keeps it forever
+type Retention struct {
+	Observations   int `json:"observations"`
+	Alerts         int `json:"alerts"` // days after they expire
+	DailySummaries int `json:"dailySummaries"`
+}
+
+// Schedule when a load job runs. Cron is a five field cron expression or a descriptor such as
+// @hourly, otherwise the job runs every Every seconds
+type Schedule struct {
+	Job    string `json:"job"` // stations, features, observations, alerts or retention
+	Cron   string `json:"cron"`
+	Every  int    `json:"every"`  // seconds
+	Jitter int    `json:"jitter"` // seconds
 }
 
 // ReadConfig read the configuraion file
@@ -6,9 +6,9 @@ require (
 	github.com/elastic/go-elasticsearch v0.0.0
 	github.com/elastic/go-elasticsearch/v8 v8.0.0-20200508105138-fc4f6f3c7fc3
 	github.com/go-resty/resty/v2 v2.2.0
-	github.com/google/uuid v1.1.1 // indirect
+	github.com/google/uuid v1.1.1
 	github.com/gorilla/mux v1.7.4
-
+	go.etcd.io/bbolt v1.3.5
 )
 
 go 1.13
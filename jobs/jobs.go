@@ -0,0 +1,318 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// State where a job is in its life
+type State string
+
+// The states of a Job
+const (
+	Queued    State = "queued"
+	Running   State = "running"
+	Succeeded State = "succeeded"
+	Failed    State = "failed"
+)
+
+// maxErrors the most errors kept for a job
+const maxErrors = 100
+
+// DefaultKeep the finished jobs remembered when none is given
+const DefaultKeep = 100
+
+// ErrNotFound there is no job with the id
+var ErrNotFound = errors.New("No such job")
+
+// Progress counts reported by a running job
+type Progress struct {
+	Pages   int    `json:"pages"`
+	Indexed uint64 `json:"indexed"`
+	Failed  uint64 `json:"failed"`
+	Deleted int64  `json:"deleted,omitempty"`
+}
+
+// Job a unit of background work and what has happened to it
+type Job struct {
+	ID       string     `json:"id"`
+	Kind     string     `json:"kind"`
+	State    State      `json:"state"`
+	Progress Progress   `json:"progress"`
+	Errors   []string   `json:"errors,omitempty"` // at most the first 100
+	Queued   time.Time  `json:"queued"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// MarshalJSON add how long the job has run
+func (j Job) MarshalJSON() ([]byte, error) {
+	type job Job
+
+	var duration string
+
+	if j.Started != nil {
+		end := time.Now()
+
+		if j.Finished != nil {
+			end = *j.Finished
+		}
+
+		duration = end.Sub(*j.Started).Round(time.Millisecond).String()
+	}
+
+	return json.Marshal(struct {
+		job
+		Duration string `json:"duration,omitempty"`
+	}{job(j), duration})
+}
+
+// Done the job has succeeded or failed
+func (j Job) Done() bool {
+	return j.State == Succeeded || j.State == Failed
+}
+
+// Func the work of a job. The context is cancelled when the manager is closed
+type Func func(ctx context.Context, reporter *Reporter) error
+
+// Reporter lets a running job report its progress and the errors it carried on past
+type Reporter struct {
+	manager *Manager
+	id      string
+}
+
+// Progress replace the job's progress counts
+func (r *Reporter) Progress(progress Progress) {
+	r.manager.update(r.id, func(job *Job) {
+		job.Progress = progress
+	})
+}
+
+// Error record an error the job carried on past
+func (r *Reporter) Error(err error) {
+	r.manager.update(r.id, func(job *Job) {
+		addError(job, err)
+	})
+}
+
+func addError(job *Job, err error) {
+	if len(job.Errors) < maxErrors {
+		job.Errors = append(job.Errors, err.Error())
+	}
+}
+
+// Manager runs the jobs in the background, a few at a time, and remembers the recent ones
+type Manager struct {
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	done    map[string]chan struct{}
+	keep    int
+	slots   chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewManager run at most workers jobs at once, remembering the last keep finished jobs.
+// Zero workers is one and zero keep is DefaultKeep
+func NewManager(workers int, keep int) *Manager {
+
+	if workers <= 0 {
+		workers = 1
+	}
+
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Manager{
+		jobs:   make(map[string]*Job),
+		done:   make(map[string]chan struct{}),
+		keep:   keep,
+		slots:  make(chan struct{}, workers),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Submit queue the work and return the job straight away
+func (m *Manager) Submit(kind string, fn Func) Job {
+
+	job := &Job{
+		ID:     uuid.New().String(),
+		Kind:   kind,
+		State:  Queued,
+		Queued: time.Now().UTC(),
+	}
+
+	done := make(chan struct{})
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.done[job.ID] = done
+	snapshot := copyJob(job)
+	m.mu.Unlock()
+
+	m.running.Add(1)
+
+	go m.run(job.ID, fn, done)
+
+	return snapshot
+}
+
+func (m *Manager) run(id string, fn Func, done chan struct{}) {
+
+	defer m.running.Done()
+	defer close(done)
+
+	select {
+	case m.slots <- struct{}{}:
+	case <-m.ctx.Done():
+		m.finish(id, m.ctx.Err())
+		return
+	}
+
+	defer func() { <-m.slots }()
+
+	m.update(id, func(job *Job) {
+		now := time.Now().UTC()
+		job.State = Running
+		job.Started = &now
+	})
+
+	m.finish(id, fn(m.ctx, &Reporter{manager: m, id: id}))
+}
+
+func (m *Manager) finish(id string, err error) {
+
+	m.update(id, func(job *Job) {
+		now := time.Now().UTC()
+		job.Finished = &now
+		job.State = Succeeded
+
+		if err != nil {
+			job.State = Failed
+			addError(job, err)
+			log.Printf("Job %s %s failed: %s", job.Kind, job.ID, err)
+		}
+	})
+
+	m.prune()
+}
+
+func (m *Manager) update(id string, fn func(job *Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// prune forget the oldest finished jobs past the number kept
+func (m *Manager) prune() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	finished := make([]*Job, 0, len(m.jobs))
+
+	for _, job := range m.jobs {
+		if job.Done() {
+			finished = append(finished, job)
+		}
+	}
+
+	if len(finished) <= m.keep {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].Finished.Before(*finished[j].Finished)
+	})
+
+	for _, job := range finished[:len(finished)-m.keep] {
+		delete(m.jobs, job.ID)
+		delete(m.done, job.ID)
+	}
+}
+
+// Get the job with the id
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+
+	if !ok {
+		return Job{}, false
+	}
+
+	return copyJob(job), true
+}
+
+// List the jobs, the most recently queued first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]Job, 0, len(m.jobs))
+
+	for _, job := range m.jobs {
+		list = append(list, copyJob(job))
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Queued.After(list[j].Queued)
+	})
+
+	return list
+}
+
+// Wait until the job is done or the context is
+func (m *Manager) Wait(ctx context.Context, id string) (Job, error) {
+
+	m.mu.Lock()
+	done, ok := m.done[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+
+	job, ok := m.Get(id)
+
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+
+	return job, nil
+}
+
+// Close cancel the running jobs and wait for them to stop
+func (m *Manager) Close() {
+	m.cancel()
+	m.running.Wait()
+}
+
+// copyJob a copy the caller can keep while the job carries on
+func copyJob(job *Job) Job {
+	c := *job
+	c.Errors = append([]string(nil), job.Errors...)
+
+	return c
+}
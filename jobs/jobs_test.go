@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestJobSucceeds(t *testing.T) {
+
+	manager := NewManager(1, 10)
+
+	defer manager.Close()
+
+	job := manager.Submit("stations", func(ctx context.Context, reporter *Reporter) error {
+		reporter.Progress(Progress{Pages: 2, Indexed: 10, Failed: 1})
+		reporter.Error(fmt.Errorf("KSFO failed"))
+		return nil
+	})
+
+	if job.ID == "" || job.Kind != "stations" {
+		t.Errorf("Submitted job not as expected. %+v\n", job)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job, err := manager.Wait(ctx, job.ID)
+
+	if err != nil {
+		t.Fatalf("Waiting for the job failed. %+v\n", err)
+	}
+
+	if job.State != Succeeded || job.Progress.Indexed != 10 || job.Progress.Pages != 2 || len(job.Errors) != 1 {
+		t.Errorf("Finished job not as expected. %+v\n", job)
+	}
+
+	if job.Started == nil || job.Finished == nil {
+		t.Errorf("Expected the job timings. %+v\n", job)
+	}
+}
+
+func TestJobFails(t *testing.T) {
+
+	manager := NewManager(1, 10)
+
+	defer manager.Close()
+
+	job := manager.Submit("features", func(ctx context.Context, reporter *Reporter) error {
+		return errors.New("Status code returned: 503")
+	})
+
+	job, err := manager.Wait(context.Background(), job.ID)
+
+	if err != nil || job.State != Failed || len(job.Errors) != 1 || job.Errors[0] != "Status code returned: 503" {
+		t.Errorf("Expected a failed job. %+v %+v\n", job, err)
+	}
+
+	if _, err = manager.Wait(context.Background(), "Goober"); err != ErrNotFound {
+		t.Errorf("Expected no job 'Goober'. %+v\n", err)
+	}
+}
+
+func TestJobsQueueAndPrune(t *testing.T) {
+
+	manager := NewManager(1, 2)
+
+	defer manager.Close()
+
+	release := make(chan struct{})
+
+	running := make(chan struct{})
+
+	first := manager.Submit("stations", func(ctx context.Context, reporter *Reporter) error {
+		close(running)
+		<-release
+		return nil
+	})
+
+	// the first has the only worker
+	<-running
+
+	second := manager.Submit("features", func(ctx context.Context, reporter *Reporter) error {
+		return nil
+	})
+
+	// the second waits for the only worker
+	time.Sleep(50 * time.Millisecond)
+
+	if job, _ := manager.Get(second.ID); job.State != Queued {
+		t.Errorf("Expected the second job queued. %+v\n", job)
+	}
+
+	close(release)
+
+	manager.Wait(context.Background(), first.ID)
+	manager.Wait(context.Background(), second.ID)
+
+	third := manager.Submit("alerts", func(ctx context.Context, reporter *Reporter) error {
+		return nil
+	})
+
+	manager.Wait(context.Background(), third.ID)
+
+	list := manager.List()
+
+	if len(list) != 2 || list[0].ID != third.ID {
+		t.Errorf("Expected the 2 most recent jobs. %+v\n", list)
+	}
+}
+
+func TestCloseCancelsJobs(t *testing.T) {
+
+	manager := NewManager(1, 10)
+
+	job := manager.Submit("stations", func(ctx context.Context, reporter *Reporter) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	manager.Close()
+
+	job, _ = manager.Get(job.ID)
+
+	if job.State != Failed {
+		t.Errorf("Expected the cancelled job to fail. %+v\n", job)
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/cache"
+	"github.com/EdSwArchitect/go-weather/config"
+	"github.com/EdSwArchitect/go-weather/jobs"
+	"github.com/EdSwArchitect/go-weather/rollup"
+	"github.com/EdSwArchitect/go-weather/schedule"
+	"github.com/EdSwArchitect/go-weather/weather"
+	"github.com/gorilla/mux"
+)
+
+// The kinds of load job
+const (
+	stationsJobKind     = "stations"
+	featuresJobKind     = "features"
+	alertsJobKind       = "alerts"
+	observationsJobKind = "observations"
+	retentionJobKind    = "retention"
+)
+
+// loadJobs the load jobs by kind, as named in the schedules
+var loadJobs = map[string]jobs.Func{
+	stationsJobKind:     loadStationsJob,
+	featuresJobKind:     loadFeaturesJob,
+	alertsJobKind:       loadAlertsJob,
+	observationsJobKind: loadObservationsJob,
+	retentionJobKind:    retentionJob,
+}
+
+// insertPage put one page of the stations list into the store
+type insertPage func(ctx context.Context, page weather.StationPage) (cache.BulkResult, error)
+
+// loadPages walk the stations list, inserting each page and reporting the counts so far. Documents
+// that failed are reported and the load carries on, any other error stops it
+func loadPages(ctx context.Context, reporter *jobs.Reporter, kind string, insert insertPage) error {
+
+	var result cache.BulkResult
+
+	pages := 0
+
+	err := weather.EachStationPageContext(ctx, func(page weather.StationPage) error {
+		indexed, err := insert(ctx, page)
+
+		pages++
+		result.Add(indexed)
+		reportBulk(reporter, pages, result, indexed)
+
+		var bulkErr *cache.BulkError
+
+		if errors.As(err, &bulkErr) {
+			return nil
+		}
+
+		return err
+	})
+
+	log.Printf("Loaded %d %s with %d failures in %s", result.Indexed, kind, result.Failed, result.Duration)
+
+	if err != nil {
+		return err
+	}
+
+	if result.Failed > 0 {
+		return &cache.BulkError{Result: result}
+	}
+
+	return nil
+}
+
+// reportBulk report the counts so far and the documents of the last insert that failed
+func reportBulk(reporter *jobs.Reporter, pages int, total cache.BulkResult, last cache.BulkResult) {
+
+	reporter.Progress(jobs.Progress{Pages: pages, Indexed: total.Indexed, Failed: total.Failed})
+
+	for _, failure := range last.Failures {
+		reporter.Error(fmt.Errorf("%s %s: %s", failure.ID, failure.Type, failure.Reason))
+	}
+}
+
+// loadStationsJob load the station URLs into the stations index
+func loadStationsJob(ctx context.Context, reporter *jobs.Reporter) error {
+	return loadPages(ctx, reporter, stationsJobKind, func(ctx context.Context, page weather.StationPage) (cache.BulkResult, error) {
+		return store.InsertStationList(ctx, stationsURI, page.ObservationStations)
+	})
+}
+
+// loadFeaturesJob load the station features into the features index
+func loadFeaturesJob(ctx context.Context, reporter *jobs.Reporter) error {
+	return loadPages(ctx, reporter, featuresJobKind, func(ctx context.Context, page weather.StationPage) (cache.BulkResult, error) {
+		return store.InsertFeatures(ctx, featuresURI, page.Features)
+	})
+}
+
+// loadAlertsJob load the active alerts into the alerts index. An alert whose zones can not all be read is
+// reported and loaded with the zones that were
+func loadAlertsJob(ctx context.Context, reporter *jobs.Reporter) error {
+
+	alerts, err := weather.GetActiveAlertsContext(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Unable to get active alerts. %s", err)
+	}
+
+	// alerts that only list their zones are matched by point against the zones' outlines
+	for i := range alerts {
+
+		alerts[i], err = weather.ResolveAlertAreaContext(ctx, alerts[i])
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			reporter.Error(fmt.Errorf("%s: %s", alerts[i].ID, err))
+		}
+	}
+
+	result, err := store.InsertAlerts(ctx, alertsURI, alerts)
+
+	reportBulk(reporter, 1, result, result)
+
+	log.Printf("Loaded %d alerts with %d failures in %s", result.Indexed, result.Failed, result.Duration)
+
+	return err
+}
+
+// loadObservationsJob load the latest observation of each station in the watchlist, filling the
+// measurements it is missing from the raw message, then recompute the daily summaries of the days
+// loaded. A station that can not be read is reported and the rest are still loaded
+func loadObservationsJob(ctx context.Context, reporter *jobs.Reporter) error {
+
+	if len(watchlist) == 0 {
+		return nil
+	}
+
+	observations := make([]weather.Observation, 0, len(watchlist))
+
+	for _, stationID := range watchlist {
+
+		observation, err := weather.GetLatestObservationContext(ctx, stationID)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			reporter.Error(fmt.Errorf("%s: %s", stationID, err))
+			continue
+		}
+
+		observations = append(observations, observation.Enrich())
+	}
+
+	if len(observations) == 0 {
+		return fmt.Errorf("No observations read for the %d stations in the watchlist", len(watchlist))
+	}
+
+	result, err := store.InsertObservations(ctx, observationsURI, observations)
+
+	reportBulk(reporter, 1, result, result)
+
+	log.Printf("Loaded %d observations with %d failures in %s", result.Indexed, result.Failed, result.Duration)
+
+	if err != nil {
+		return err
+	}
+
+	summarized, err := rollup.Update(ctx, store, storeIndices(), observations, time.Now())
+
+	log.Printf("Summarized %d station days with %d failures in %s", summarized.Indexed, summarized.Failed, summarized.Duration)
+
+	return err
+}
+
+// retentionJob delete the observations and alerts older than the configured retention
+func retentionJob(ctx context.Context, reporter *jobs.Reporter) error {
+
+	result, err := store.ApplyRetention(ctx, storeIndices(), retention, time.Now())
+
+	reporter.Progress(jobs.Progress{Deleted: result.DeletedDocuments + int64(len(result.DeletedIndices))})
+
+	log.Printf("Retention deleted %d indices and %d documents", len(result.DeletedIndices), result.DeletedDocuments)
+
+	return err
+}
+
+// newScheduler schedule the configured load jobs
+func newScheduler(schedules []config.Schedule) (*schedule.Scheduler, error) {
+
+	s := schedule.New(jobManager)
+
+	for _, sched := range schedules {
+
+		fn, ok := loadJobs[sched.Job]
+
+		if !ok {
+			return nil, fmt.Errorf("Unknown job %q", sched.Job)
+		}
+
+		var spec schedule.Spec = schedule.Every(time.Duration(sched.Every) * time.Second)
+
+		if sched.Cron != "" {
+			var err error
+
+			if spec, err = schedule.Parse(sched.Cron); err != nil {
+				return nil, err
+			}
+		} else if sched.Every <= 0 {
+			return nil, fmt.Errorf("Job %s has neither a cron expression nor an interval", sched.Job)
+		}
+
+		if err := s.Add(sched.Job, spec, time.Duration(sched.Jitter)*time.Second, fn); err != nil {
+			return nil, err
+		}
+
+		log.Printf("Scheduled %s %s", sched.Job, spec)
+	}
+
+	return s, nil
+}
+
+// submitJob queue the load and answer with the job straight away
+func submitJob(w http.ResponseWriter, kind string, fn jobs.Func) {
+
+	job := jobManager.Submit(kind, fn)
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+
+	writeJSONStatus(w, http.StatusAccepted, job)
+}
+
+func loadStations(w http.ResponseWriter, r *http.Request) {
+	submitJob(w, stationsJobKind, loadStationsJob)
+}
+
+func loadFeatures(w http.ResponseWriter, r *http.Request) {
+	submitJob(w, featuresJobKind, loadFeaturesJob)
+}
+
+func loadAlerts(w http.ResponseWriter, r *http.Request) {
+	submitJob(w, alertsJobKind, loadAlertsJob)
+}
+
+func loadObservations(w http.ResponseWriter, r *http.Request) {
+	submitJob(w, observationsJobKind, loadObservationsJob)
+}
+
+func applyRetention(w http.ResponseWriter, r *http.Request) {
+	submitJob(w, retentionJobKind, retentionJob)
+}
+
+func getIndexStats(w http.ResponseWriter, r *http.Request) {
+
+	stats, err := store.IndexStats(r.Context(), storeIndices())
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Unable to read the indices. %s", err)
+		return
+	}
+
+	writeJSON(w, stats)
+}
+
+func getSchedules(w http.ResponseWriter, r *http.Request) {
+
+	if scheduler == nil {
+		writeJSON(w, []schedule.Status{})
+		return
+	}
+
+	writeJSON(w, scheduler.Status())
+}
+
+func getJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, jobManager.List())
+}
+
+func getJob(w http.ResponseWriter, r *http.Request) {
+
+	jobID := mux.Vars(r)["jobId"]
+
+	job, ok := jobManager.Get(jobID)
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "No job %s found", jobID)
+		return
+	}
+
+	writeJSON(w, job)
+}
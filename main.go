@@ -1,58 +1,139 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"flag"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/EdSwArchitect/go-weather/cache"
 	"github.com/EdSwArchitect/go-weather/config"
+	"github.com/EdSwArchitect/go-weather/jobs"
+	"github.com/EdSwArchitect/go-weather/schedule"
 	"github.com/EdSwArchitect/go-weather/weather"
 	"github.com/gorilla/mux"
 )
 
 var espUri string
 var configFile string
-var featuresURI string
-var stationsURI string
+var featuresURI = "features"
+var stationsURI = "stations"
+var alertsURI = "alerts"
+var observationsURI = cache.DefaultIndices.Observations
+var summariesURI = cache.DefaultIndices.DailySummaries
 var httpPort int
+var storeKind string
+var dataPath string
+var featureTTL = 24 * time.Hour
+var requestTimeout time.Duration
+var store cache.Store
+var jobManager = jobs.NewManager(2, jobs.DefaultKeep)
+var watchlist []string
+var schedules []config.Schedule
+var scheduler *schedule.Scheduler
+var runSchedules bool
+var retention cache.Retention
+
+// withTimeout cancels the request context after the request timeout, stopping the calls to the
+// weather service and the store made for it
+func withTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestTimeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-func init() {
+		ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-	flag.StringVar(&espUri, "espUri", "localhost:9200", "The ESP host and port number")
-	flag.IntVar(&httpPort, "serverPort", 8080, "The HTTP server port")
-	flag.StringVar(&configFile, "configFile", "", "The configuration file")
+// unitsKey the request context key of the unit system
+type unitsKey struct{}
 
-	flag.Parse()
+// withUnits rejects an unknown units parameter and keeps the unit system in the request context.
+// Without the parameter the responses are left in the weather service's units
+func withUnits(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		units := r.URL.Query().Get("units")
 
-	if configFile != "" {
-		log.Printf("Working with log file: %s", configFile)
+		if units == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		config, err := config.ReadConfig(&configFile)
+		system, err := weather.ParseUnitSystem(units)
 
 		if err != nil {
-			log.Fatalf("Configuration failed: %s", err)
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
 		}
 
-		espUri = config.EspURI
-		featuresURI = config.FeaturesURI
-		stationsURI = config.StationsURI
-		httpPort = config.ServerPort
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), unitsKey{}, system)))
+	})
+}
+
+// inUnits the response with its quantities in the request's unit system. Alerts have no measurements
+// and a TAF keeps the aviation units it is coded in, knots, statute miles and feet, so neither is converted
+func inUnits(r *http.Request, v interface{}) interface{} {
+
+	system, ok := r.Context().Value(unitsKey{}).(weather.UnitSystem)
 
+	if !ok {
+		return v
 	}
 
-	log.Printf("espURI: %s", espUri)
-	log.Printf("configFile: %s", configFile)
-	log.Printf("featuresURI: %s", featuresURI)
-	log.Printf("stationsURI: %s", stationsURI)
-	log.Printf("httpPort: %d", httpPort)
+	switch v := v.(type) {
+	case weather.Feature:
+		return v.Units(system)
+	case []weather.Feature:
+		features := make([]weather.Feature, len(v))
+
+		for i, feature := range v {
+			features[i] = feature.Units(system)
+		}
+
+		return features
+	case weather.FeatureCollection:
+		return v.Units(system)
+	case []cache.StationDistance:
+		stations := make([]cache.StationDistance, len(v))
+
+		for i, station := range v {
+			station.Feature = station.Feature.Units(system)
+			stations[i] = station
+		}
+
+		return stations
+	case weather.Observation:
+		return v.Units(system)
+	case []weather.Observation:
+		observations := make([]weather.Observation, len(v))
 
-	// cache.Initialize("localhost:9200")
-	cache.Initialize(espUri)
+		for i, observation := range v {
+			observations[i] = observation.Units(system)
+		}
+
+		return observations
+	case weather.Forecast:
+		return v.Units(system)
+	case []cache.DailySummary:
+		summaries := make([]cache.DailySummary, len(v))
+
+		for i, summary := range v {
+			summaries[i] = summary.Units(system)
+		}
+
+		return summaries
+	}
+
+	return v
 }
 
 func heartBeat(w http.ResponseWriter, r *http.Request) {
@@ -62,207 +143,474 @@ func heartBeat(w http.ResponseWriter, r *http.Request) {
 
 func getStations(w http.ResponseWriter, r *http.Request) {
 
-	count, err := cache.IndexCount("stations")
+	query := r.URL.Query()
 
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "%s\n", err)
-		return
+	filter := cache.StationFilter{
+		State:    query.Get("state"),
+		County:   query.Get("county"),
+		TimeZone: query.Get("timeZone"),
+		Name:     query.Get("name"),
 	}
 
-	if count == 0 {
-
-		theStations, err := weather.GetObservationStations()
+	if bbox := query.Get("bbox"); bbox != "" {
+		box, err := cache.ParseBoundingBox(bbox)
 
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Header().Add("content-type", "text/plain; charset=utf-8")
-			fmt.Fprintf(w, "%s\n", err)
+			writeError(w, http.StatusBadRequest, "%s", err)
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
-		w.Header().Add("content-type", "application/json; charset=utf-8")
+		filter.BBox = &box
+	}
 
-		fmt.Fprintf(w, "%s", theStations.ObservationStations)
-	} else {
-		//		cache.GetStations()
-		stations, err := cache.GetStationList("stations")
+	writeStationPage(w, r, filter)
+}
 
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Header().Add("content-type", "text/plain; charset=utf-8")
-			fmt.Fprintf(w, "%s\n", err)
-			return
-		}
+// getFeatures every loaded station feature, a page at a time
+func getFeatures(w http.ResponseWriter, r *http.Request) {
+	writeStationPage(w, r, cache.StationFilter{})
+}
 
-		w.WriteHeader(http.StatusOK)
-		w.Header().Add("content-type", "application/json; charset=utf-8")
+// writeStationPage the page of the loaded features passing the filter as a GeoJSON feature collection,
+// with the cursor of the next page. The stations are only searched once they are loaded, walking the
+// weather service's list is a job. A store that fails to answer is a bad gateway
+func writeStationPage(w http.ResponseWriter, r *http.Request, filter cache.StationFilter) {
 
-		for _, station := range stations {
-			fmt.Fprintf(w, "%s\n", station)
-		}
+	pageSize, err := parseIntParam(r, "pageSize", cache.DefaultPageSize)
+
+	if err != nil || pageSize <= 0 || pageSize > cache.MaxPageSize {
+		writeError(w, http.StatusBadRequest, "pageSize must be between 1 and %d", cache.MaxPageSize)
+		return
+	}
+
+	filter.Limit = pageSize
+	filter.Cursor = r.URL.Query().Get("page")
+
+	count, err := store.IndexCount(r.Context(), featuresURI)
+
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "%s\n", err)
+		return
+	}
+
+	if count == 0 {
+		writeError(w, http.StatusServiceUnavailable, "No stations loaded yet. POST /loadFeatures to load them")
+		return
+	}
+
+	page, err := store.SearchStations(r.Context(), featuresURI, filter)
 
-		// fmt.Fprintf(w, "%s", stations)
+	if errors.Is(err, cache.ErrInvalidCursor) {
+		writeError(w, http.StatusBadRequest, "%s\n", err)
+		return
+	}
 
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "%s\n", err)
+		return
 	}
+
+	collection := weather.NewFeatureCollection(page.Features)
+	collection.Next = page.Next
+
+	writeGeoJSON(w, inUnits(r, collection))
 }
 
-func loadStations(w http.ResponseWriter, r *http.Request) {
+func getNearStations(w http.ResponseWriter, r *http.Request) {
 
-	theStations, err := weather.GetObservationStations()
+	lat, lon, err := parseLatLon(r)
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "%s\n", err)
+		writeError(w, http.StatusBadRequest, "%s", err)
 		return
 	}
 
-	cache.InsertStationList(stationsURI, theStations.ObservationStations)
+	radius, err := parseFloatParam(r, "radius", 50)
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Add("content-type", "text/plain; charset=utf-8")
+	if err != nil || radius <= 0 {
+		writeError(w, http.StatusBadRequest, "radius must be a positive number of kilometers")
+		return
+	}
+
+	limit, err := parseIntParam(r, "limit", 10)
+
+	if err != nil || limit <= 0 || limit > 100 {
+		writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+		return
+	}
+
+	stations, err := store.NearestStations(r.Context(), featuresURI, lat, lon, radius, limit)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%s", err)
+		return
+	}
 
-	fmt.Fprint(w, "OK")
+	writeJSON(w, inUnits(r, stations))
 }
 
+// getStation the station's feature, read through the features cache. Served as /station and /feature
 func getStation(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
 	stationID := vars["stationId"]
 
 	if stationID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "No stationId given")
+		writeError(w, http.StatusBadRequest, "No stationId given")
 		return
 	}
 
-	feature, err := weather.GetFeature(stationID)
+	feature, err := cache.LookupFeatureContext(r.Context(), store, featuresURI, stationID, featureTTL, weather.GetFeatureContext)
 
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "No stationId %s found", stationID)
+		writeError(w, lookupStatus(err), "No stationId %s found. %s", stationID, err)
 		return
 	}
 
-	b, err := json.Marshal(feature)
+	writeJSON(w, inUnits(r, feature))
+}
+
+// parseTime parses an optional RFC 3339 query parameter
+func parseTime(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "Unable to marshal station information")
+		return time.Time{}, fmt.Errorf("Invalid %s time %q, expected RFC 3339", name, value)
+	}
+
+	return t, nil
+}
+
+// parseLatLon parses the required lat and lon query parameters
+func parseLatLon(r *http.Request) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid or missing lat %q", r.URL.Query().Get("lat"))
+	}
+
+	lon, err := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid or missing lon %q", r.URL.Query().Get("lon"))
+	}
+
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("lat %f, lon %f out of range", lat, lon)
+	}
+
+	return lat, lon, nil
+}
+
+// parseFloatParam parses an optional float query parameter
+func parseFloatParam(r *http.Request, name string, defaultValue float64) (float64, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s %q", name, value)
+	}
+
+	return f, nil
+}
+
+// parseIntParam parses an optional int query parameter
+func parseIntParam(r *http.Request, name string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s %q", name, value)
+	}
+
+	return i, nil
+}
+
+// writeError writes a plain text error response
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	w.Header().Add("content-type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, format, args...)
+}
+
+// writeJSON writes the value as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	writeJSONStatus(w, http.StatusOK, v)
+}
+
+// writeJSONStatus writes the value as a JSON response with the given status
+func writeJSONStatus(w http.ResponseWriter, status int, v interface{}) {
+	writeMarshalled(w, status, "application/json; charset=utf-8", v)
+}
+
+// writeGeoJSON writes the value as a GeoJSON response
+func writeGeoJSON(w http.ResponseWriter, v interface{}) {
+	writeMarshalled(w, http.StatusOK, "application/geo+json", v)
+}
+
+// writeMarshalled writes the value marshalled as JSON with the content type and status
+func writeMarshalled(w http.ResponseWriter, status int, contentType string, v interface{}) {
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Unable to marshal response. %s", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Add("content-type", "application/json; charset=utf-8")
+	w.Header().Add("content-type", contentType)
+	w.WriteHeader(status)
 
 	fmt.Fprintf(w, "%s", string(b))
 }
 
-func loadFeatures(w http.ResponseWriter, r *http.Request) {
+func getObservations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	stationID := vars["stationId"]
+
+	if stationID == "" {
+		writeError(w, http.StatusBadRequest, "No stationId given")
+		return
+	}
 
-	features, err := weather.GetFeatures()
+	start, err := parseTime(r, "start")
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "Unable to marshal feature information. %s", err)
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	end, err := parseTime(r, "end")
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		writeError(w, http.StatusBadRequest, "end is before start")
+		return
+	}
+
+	observations, err := weather.GetObservationsContext(r.Context(), stationID, weather.ObservationQuery{Start: start, End: end})
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "No observations for stationId %s found. %s", stationID, err)
 		return
 	}
 
-	cache.InsertFeatures(featuresURI, features)
+	writeJSON(w, inUnits(r, observations))
 }
 
-func getFeature(w http.ResponseWriter, r *http.Request) {
+func getLatestObservation(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
-	fmt.Fprintf(w, "The vars: %+v", vars)
+	stationID := vars["stationId"]
+
+	if stationID == "" {
+		writeError(w, http.StatusBadRequest, "No stationId given")
+		return
+	}
+
+	observation, err := weather.GetLatestObservationContext(r.Context(), stationID)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "No observation for stationId %s found. %s", stationID, err)
+		return
+	}
+
+	writeJSON(w, inUnits(r, observation))
+}
+
+func getForecast(w http.ResponseWriter, r *http.Request) {
+
+	lat, lon, err := parseLatLon(r)
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	forecast, err := weather.GetForecastContext(r.Context(), lat, lon)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "No forecast for %f,%f found. %s", lat, lon, err)
+		return
+	}
+
+	writeJSON(w, inUnits(r, forecast))
+}
+
+func getHourlyForecast(w http.ResponseWriter, r *http.Request) {
+
+	lat, lon, err := parseLatLon(r)
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	forecast, err := weather.GetHourlyForecastContext(r.Context(), lat, lon)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "No hourly forecast for %f,%f found. %s", lat, lon, err)
+		return
+	}
+
+	writeJSON(w, inUnits(r, forecast))
+}
+
+// lookupStatus the status of a failed station lookup. Only a station the weather service does not
+// know is not found, anything else is the weather service failing
+func lookupStatus(err error) int {
+
+	if errors.Is(err, weather.ErrNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadGateway
+}
+
+func getStationForecast(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
 
 	stationID := vars["stationId"]
 
 	if stationID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "No stationId given")
+		writeError(w, http.StatusBadRequest, "No stationId given")
 		return
 	}
 
-	feature, err := weather.GetFeature(stationID)
+	feature, err := cache.LookupFeatureContext(r.Context(), store, featuresURI, stationID, featureTTL, weather.GetFeatureContext)
 
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "No stationId %s found", stationID)
+		writeError(w, lookupStatus(err), "No stationId %s found. %s", stationID, err)
 		return
 	}
 
-	b, err := json.Marshal(feature)
+	forecast, err := weather.GetStationForecastContext(r.Context(), feature)
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "Unable to marshal station information")
+		writeError(w, http.StatusBadGateway, "No forecast for stationId %s. %s", stationID, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Add("content-type", "application/json; charset=utf-8")
+	writeJSON(w, inUnits(r, forecast))
+}
 
-	fmt.Fprintf(w, "%s", string(b))
+func getStationTAF(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	stationID := vars["stationId"]
+
+	if stationID == "" {
+		writeError(w, http.StatusBadRequest, "No stationId given")
+		return
+	}
+
+	forecast, err := weather.GetTAFContext(r.Context(), stationID)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "No TAF for stationId %s found. %s", stationID, err)
+		return
+	}
+
+	// the units parameter does not apply, a TAF keeps its coded units
+	writeJSON(w, forecast)
 }
 
-func getFeatures(w http.ResponseWriter, r *http.Request) {
+func getDailySummaries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
 
-	// count, err := cache.IndexCount("features")
+	stationID := vars["stationId"]
 
-	// if err != nil {
-	// 	w.WriteHeader(http.StatusInternalServerError)
-	// 	w.Header().Add("content-type", "text/plain; charset=utf-8")
-	// 	fmt.Fprintf(w, "%s\n", err)
-	// 	return
-	// }
+	if stationID == "" {
+		writeError(w, http.StatusBadRequest, "No stationId given")
+		return
+	}
 
-	// if count == 0 {
+	query := r.URL.Query()
 
-	theFeatures, err := weather.GetFeatures()
+	// the last 30 days unless a range is given
+	to := time.Now().UTC().Format(cache.SummaryDateFormat)
+	from := time.Now().UTC().AddDate(0, 0, -30).Format(cache.SummaryDateFormat)
+
+	for name, value := range map[string]*string{"from": &from, "to": &to} {
+		if query.Get(name) == "" {
+			continue
+		}
+
+		if _, err := time.Parse(cache.SummaryDateFormat, query.Get(name)); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid %s %q, expected YYYY-MM-DD", name, query.Get(name))
+			return
+		}
+
+		*value = query.Get(name)
+	}
+
+	if to < from {
+		writeError(w, http.StatusBadRequest, "to is before from")
+		return
+	}
+
+	summaries, err := store.DailySummaries(r.Context(), summariesURI, stationID, from, to)
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Add("content-type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "%s\n", err)
+		writeError(w, http.StatusInternalServerError, "Unable to read the daily summaries for stationId %s. %s", stationID, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Add("content-type", "application/json; charset=utf-8")
+	writeJSON(w, inUnits(r, summaries))
+}
 
-	fmt.Fprintf(w, "%v", theFeatures)
-	// } else {
-	//		cache.GetStations()
-	// 	stations, err := cache.
+func getAlerts(w http.ResponseWriter, r *http.Request) {
 
-	// 	if err != nil {
-	// 		w.WriteHeader(http.StatusInternalServerError)
-	// 		w.Header().Add("content-type", "text/plain; charset=utf-8")
-	// 		fmt.Fprintf(w, "%s\n", err)
-	// 		return
-	// 	}
+	query := r.URL.Query()
 
-	// 	w.WriteHeader(http.StatusOK)
-	// 	w.Header().Add("content-type", "application/json; charset=utf-8")
+	filter := cache.AlertFilter{
+		State:    query.Get("state"),
+		Event:    query.Get("event"),
+		Severity: query.Get("severity"),
+	}
 
-	// 	for _, station := range stations {
-	// 		fmt.Fprintf(w, "%s\n", station)
-	// 	}
+	if query.Get("lat") != "" || query.Get("lon") != "" {
+		lat, lon, err := parseLatLon(r)
 
-	// 	// fmt.Fprintf(w, "%s", stations)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+
+		filter.ByPoint = true
+		filter.Lat = lat
+		filter.Lon = lon
+	}
+
+	alerts, err := store.SearchAlerts(r.Context(), alertsURI, filter)
 
-	// }
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%s", err)
+		return
+	}
+
+	// the units parameter does not apply, alerts have no measurements
+	writeJSON(w, alerts)
 }
 
 func writeStatic(w http.ResponseWriter, r *http.Request) {
@@ -303,19 +651,3 @@ func writeStatic(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("content-type", "text/plain; charset=utf-8")
 	fmt.Fprintf(w, "OK")
 }
-
-func main() {
-
-	router := mux.NewRouter()
-
-	router.HandleFunc("/", heartBeat)
-	router.HandleFunc("/stations", getStations)
-	router.HandleFunc("/features", getFeatures)
-	router.HandleFunc("/loadStations", loadStations)
-	router.HandleFunc("/station/{stationId}", getStation)
-	router.HandleFunc("/loadFeatures", loadFeatures)
-	router.HandleFunc("/feature/{stationId}", getFeature)
-	router.HandleFunc("/writeStatic/{saticID}", writeStatic)
-
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", httpPort), router))
-}
@@ -0,0 +1,194 @@
+// Package rollup aggregates the stored observations of a station into daily summaries, recomputing the days
+// new observations fall on
+package rollup
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/cache"
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// compass the 16 points of the compass, clockwise from north
+var compass = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// Day the UTC day the time falls on
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// round to hundredths, so a mean is not reported to more places than it is measured
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Summarize roll up the station's observations on the UTC day. Observations on other days are ignored.
+// The precipitation is summed over the hours, taking the largest amount reported for each hour so the
+// special reports within an hour are not counted again. The prevailing wind direction is the middle of
+// the compass point the wind blew from most often, calm and variable winds aside
+func Summarize(stationID string, day time.Time, observations []weather.Observation) cache.DailySummary {
+
+	day = Day(day)
+
+	summary := cache.DailySummary{StationID: stationID, Date: day.Format(cache.SummaryDateFormat)}
+
+	var minTemperature, maxTemperature, sumTemperature, maxGust float64
+	var temperatures, gusts int
+
+	precipitation := make(map[time.Time]float64)
+	directions := make([]int, len(compass))
+
+	for _, observation := range observations {
+		p := observation.Props
+
+		if !Day(p.Timestamp).Equal(day) {
+			continue
+		}
+
+		if summary.Observations == 0 || p.Timestamp.Before(summary.First) {
+			summary.First = p.Timestamp
+		}
+
+		if summary.Observations == 0 || p.Timestamp.After(summary.Last) {
+			summary.Last = p.Timestamp
+		}
+
+		summary.Observations++
+
+		if t, ok := p.Temperature.In(weather.Celsius); ok {
+			if temperatures == 0 || t < minTemperature {
+				minTemperature = t
+			}
+
+			if temperatures == 0 || t > maxTemperature {
+				maxTemperature = t
+			}
+
+			sumTemperature += t
+			temperatures++
+		}
+
+		if mm, ok := p.PrecipitationLastHour.In(weather.Millimeter); ok {
+			hour := p.Timestamp.UTC().Truncate(time.Hour)
+
+			if last, seen := precipitation[hour]; !seen || mm > last {
+				precipitation[hour] = mm
+			}
+		}
+
+		if gust, ok := p.WindGust.In(weather.KilometersPerHour); ok {
+			if gusts == 0 || gust > maxGust {
+				maxGust = gust
+			}
+
+			gusts++
+		}
+
+		speed, _ := p.WindSpeed.In(weather.KilometersPerHour)
+
+		if direction, ok := p.WindDirection.In(weather.Degree); ok && speed > 0 {
+			directions[int(math.Mod(direction+11.25, 360)/22.5)]++
+		}
+	}
+
+	if temperatures > 0 {
+		summary.MinTemperature = weather.NewQuantity(minTemperature, weather.Celsius)
+		summary.MaxTemperature = weather.NewQuantity(maxTemperature, weather.Celsius)
+		summary.MeanTemperature = weather.NewQuantity(round(sumTemperature/float64(temperatures)), weather.Celsius)
+	}
+
+	if len(precipitation) > 0 {
+		total := 0.0
+
+		for _, mm := range precipitation {
+			total += mm
+		}
+
+		summary.TotalPrecipitation = weather.NewQuantity(round(total), weather.Millimeter)
+	}
+
+	if gusts > 0 {
+		summary.MaxWindGust = weather.NewQuantity(maxGust, weather.KilometersPerHour)
+	}
+
+	prevailing := -1
+
+	for point, count := range directions {
+		if count > 0 && (prevailing < 0 || count > directions[prevailing]) {
+			prevailing = point
+		}
+	}
+
+	if prevailing >= 0 {
+		summary.PrevailingWindDirection = weather.NewQuantity(float64(prevailing)*22.5, weather.Degree)
+		summary.PrevailingWindCompass = compass[prevailing]
+	}
+
+	return summary
+}
+
+// stationDay a station and the UTC day of its observations
+type stationDay struct {
+	stationID string
+	day       time.Time
+}
+
+// Update recompute the summaries of the days the observations fall on from the observations already stored
+// for those days and the new ones. The new observations replace any stored at the same time, so the
+// summaries are right even when the store has not made the new observations searchable yet
+func Update(ctx context.Context, store cache.Store, indices cache.Indices, observations []weather.Observation, now time.Time) (cache.BulkResult, error) {
+
+	if len(observations) == 0 {
+		return cache.BulkResult{}, nil
+	}
+
+	days := make(map[stationDay][]weather.Observation)
+
+	for _, observation := range observations {
+		key := stationDay{observation.StationID(), Day(observation.Props.Timestamp)}
+		days[key] = append(days[key], observation)
+	}
+
+	summaries := make([]cache.DailySummary, 0, len(days))
+
+	for key, added := range days {
+
+		stored, err := store.StationObservations(ctx, indices.Observations, key.stationID, key.day, key.day.AddDate(0, 0, 1))
+
+		if err != nil {
+			return cache.BulkResult{}, err
+		}
+
+		byTime := make(map[int64]weather.Observation, len(stored)+len(added))
+
+		for _, observation := range append(stored, added...) {
+			byTime[observation.Props.Timestamp.Unix()] = observation
+		}
+
+		merged := make([]weather.Observation, 0, len(byTime))
+
+		for _, observation := range byTime {
+			merged = append(merged, observation)
+		}
+
+		summary := Summarize(key.stationID, key.day, merged)
+		summary.UpdatedAt = now
+
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].StationID != summaries[j].StationID {
+			return summaries[i].StationID < summaries[j].StationID
+		}
+
+		return summaries[i].Date < summaries[j].Date
+	})
+
+	return store.InsertDailySummaries(ctx, indices.DailySummaries, summaries)
+}
@@ -0,0 +1,122 @@
+package rollup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/cache"
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// observationAt an observation at KSFO with the temperature, wind and precipitation. A negative value is not reported
+func observationAt(t time.Time, temperature float64, direction float64, speed float64, gust float64, precipitation float64) weather.Observation {
+
+	observation := weather.Observation{}
+	observation.Props.Station = "https://api.weather.gov/stations/KSFO"
+	observation.Props.Timestamp = t
+
+	reported := func(q *weather.Quantity, value float64, unit weather.Unit) {
+		if value >= 0 {
+			*q = weather.NewQuantity(value, unit)
+		}
+	}
+
+	reported(&observation.Props.Temperature, temperature, weather.Celsius)
+	reported(&observation.Props.WindDirection, direction, weather.Degree)
+	reported(&observation.Props.WindSpeed, speed, weather.KilometersPerHour)
+	reported(&observation.Props.WindGust, gust, weather.KilometersPerHour)
+	reported(&observation.Props.PrecipitationLastHour, precipitation, weather.Millimeter)
+
+	return observation
+}
+
+func TestSummarize(t *testing.T) {
+
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	observations := []weather.Observation{
+		observationAt(day.Add(-time.Minute), 30, 90, 10, 90, 9),
+		observationAt(day.Add(56*time.Minute), 10, 275, 20, -1, 1.5),
+		// a special report within the hour, the routine report's amount covers it
+		observationAt(day.Add(30*time.Minute), 11, 280, 18, 40, 0.5),
+		observationAt(day.Add(116*time.Minute), 14, 265, 15, 55, 2),
+		// calm
+		observationAt(day.Add(176*time.Minute), 12, 90, 0, -1, -1),
+		observationAt(day.Add(236*time.Minute), 15, 350, 5, -1, -1),
+	}
+
+	summary := Summarize("KSFO", day.Add(12*time.Hour), observations)
+
+	if summary.Date != "2026-10-18" || summary.Observations != 5 {
+		t.Errorf("Expected 5 observations on 2026-10-18. %+v\n", summary)
+	}
+
+	if !summary.First.Equal(day.Add(30*time.Minute)) || !summary.Last.Equal(day.Add(236*time.Minute)) {
+		t.Errorf("First and last not as expected. %s %s\n", summary.First, summary.Last)
+	}
+
+	expect := func(name string, q weather.Quantity, unit weather.Unit, value float64) {
+		if v, ok := q.In(unit); !ok || v != value {
+			t.Errorf("Expected %s %.2f. %+v\n", name, value, q)
+		}
+	}
+
+	expect("min temperature", summary.MinTemperature, weather.Celsius, 10)
+	expect("max temperature", summary.MaxTemperature, weather.Celsius, 15)
+	expect("mean temperature", summary.MeanTemperature, weather.Celsius, 12.4)
+	expect("total precipitation", summary.TotalPrecipitation, weather.Millimeter, 3.5)
+	expect("max gust", summary.MaxWindGust, weather.KilometersPerHour, 55)
+	expect("prevailing direction", summary.PrevailingWindDirection, weather.Degree, 270)
+
+	if summary.PrevailingWindCompass != "W" {
+		t.Errorf("Expected a west wind. %s\n", summary.PrevailingWindCompass)
+	}
+
+	empty := Summarize("KSFO", day, nil)
+
+	if empty.Observations != 0 || empty.MeanTemperature.Value != nil || empty.PrevailingWindCompass != "" {
+		t.Errorf("Expected an empty summary. %+v\n", empty)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+
+	ctx := context.Background()
+	store := cache.NewMemoryStore()
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	now := day.Add(26 * time.Hour)
+
+	stored := []weather.Observation{
+		observationAt(day.Add(time.Hour), 10, -1, -1, -1, -1),
+		observationAt(day.Add(2*time.Hour), 20, -1, -1, -1, -1),
+	}
+
+	store.InsertObservations(ctx, cache.DefaultIndices.Observations, stored)
+
+	// the second observation corrected and one on the next day
+	added := []weather.Observation{
+		observationAt(day.Add(2*time.Hour), 16, -1, -1, -1, -1),
+		observationAt(day.Add(25*time.Hour), 8, -1, -1, -1, -1),
+	}
+
+	result, err := Update(ctx, store, cache.DefaultIndices, added, now)
+
+	if err != nil || result.Indexed != 2 {
+		t.Fatalf("Expected 2 days summarized. %+v %+v\n", result, err)
+	}
+
+	summaries, err := store.DailySummaries(ctx, cache.DefaultIndices.DailySummaries, "KSFO", "2026-10-01", "2026-10-31")
+
+	if err != nil || len(summaries) != 2 {
+		t.Fatalf("Expected 2 summaries. %+v %+v\n", summaries, err)
+	}
+
+	if mean, _ := summaries[0].MeanTemperature.In(weather.Celsius); summaries[0].Observations != 2 || mean != 13 {
+		t.Errorf("Expected the stored and corrected observations summarized. %+v\n", summaries[0])
+	}
+
+	if summaries[1].Date != "2026-10-19" || summaries[1].Observations != 1 || !summaries[1].UpdatedAt.Equal(now) {
+		t.Errorf("Expected the next day summarized. %+v\n", summaries[1])
+	}
+}
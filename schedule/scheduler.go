@@ -0,0 +1,209 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/jobs"
+)
+
+// entry a scheduled job and what happened the last time it ran
+type entry struct {
+	name    string
+	spec    Spec
+	jitter  time.Duration
+	fn      jobs.Func
+	next    time.Time
+	lastRun *time.Time
+	lastJob string
+	runs    int
+	skipped int
+}
+
+// Status of a scheduled job, for reporting
+type Status struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Jitter   string     `json:"jitter,omitempty"`
+	Next     *time.Time `json:"next,omitempty"`
+	LastRun  *time.Time `json:"lastRun,omitempty"`
+	LastJob  *jobs.Job  `json:"lastJob,omitempty"`
+	Runs     int        `json:"runs"`
+	Skipped  int        `json:"skipped"` // the previous run had not finished
+}
+
+// Scheduler submits jobs to the manager when their schedules come due. A job is skipped while
+// the previous run of it is still queued or running
+type Scheduler struct {
+	mu      sync.Mutex
+	manager *jobs.Manager
+	entries []*entry
+	random  *rand.Rand
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// New a scheduler submitting to the manager
+func New(manager *jobs.Manager) *Scheduler {
+	return &Scheduler{
+		manager: manager,
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Add schedule the job. Each run starts up to jitter after the scheduled time, so that many
+// instances do not call the weather service at once
+func (s *Scheduler) Add(name string, spec Spec, jitter time.Duration, fn jobs.Func) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return fmt.Errorf("Unable to add %s to a started scheduler", name)
+	}
+
+	for _, e := range s.entries {
+		if e.name == name {
+			return fmt.Errorf("Job %s is already scheduled", name)
+		}
+	}
+
+	s.entries = append(s.entries, &entry{name: name, spec: spec, jitter: jitter, fn: fn})
+
+	return nil
+}
+
+// Start running the jobs on their schedules until the context is done or Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+
+		go s.loop(ctx, e)
+	}
+}
+
+// Stop scheduling. Jobs already submitted carry on in the manager
+func (s *Scheduler) Stop() {
+
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+
+	defer s.wg.Done()
+
+	for {
+		next := e.spec.Next(time.Now())
+
+		if next.IsZero() {
+			log.Printf("Schedule %s for %s never runs again", e.spec, e.name)
+			return
+		}
+
+		s.mu.Lock()
+
+		if e.jitter > 0 {
+			next = next.Add(time.Duration(s.random.Int63n(int64(e.jitter))))
+		}
+
+		e.next = next
+
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(e)
+	}
+}
+
+// run submit the job unless its last run is still going
+func (s *Scheduler) run(e *entry) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e.lastJob != "" {
+		if job, ok := s.manager.Get(e.lastJob); ok && !job.Done() {
+			e.skipped++
+			log.Printf("Skipping %s, job %s is still %s", e.name, job.ID, job.State)
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	job := s.manager.Submit(e.name, e.fn)
+
+	e.lastRun = &now
+	e.lastJob = job.ID
+	e.runs++
+}
+
+// Status of every scheduled job, by name
+func (s *Scheduler) Status() []Status {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.entries))
+
+	for _, e := range s.entries {
+		status := Status{
+			Name:     e.name,
+			Schedule: e.spec.String(),
+			LastRun:  e.lastRun,
+			Runs:     e.runs,
+			Skipped:  e.skipped,
+		}
+
+		if e.jitter > 0 {
+			status.Jitter = e.jitter.String()
+		}
+
+		if !e.next.IsZero() {
+			next := e.next.UTC()
+			status.Next = &next
+		}
+
+		if job, ok := s.manager.Get(e.lastJob); ok {
+			status.LastJob = &job
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
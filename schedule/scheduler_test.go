@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/jobs"
+)
+
+func TestSchedulerRuns(t *testing.T) {
+
+	manager := jobs.NewManager(2, 10)
+
+	defer manager.Close()
+
+	scheduler := New(manager)
+
+	var runs int32
+
+	scheduler.Add("alerts", Every(10*time.Millisecond), 0, func(ctx context.Context, reporter *jobs.Reporter) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	if err := scheduler.Add("alerts", Every(time.Hour), 0, nil); err == nil {
+		t.Errorf("Expected the same job not to be scheduled twice\n")
+	}
+
+	scheduler.Start(context.Background())
+
+	time.Sleep(100 * time.Millisecond)
+
+	scheduler.Stop()
+
+	if atomic.LoadInt32(&runs) < 2 {
+		t.Errorf("Expected the job to run a few times. %d\n", runs)
+	}
+
+	statuses := scheduler.Status()
+
+	if len(statuses) != 1 || statuses[0].Name != "alerts" || statuses[0].LastRun == nil || statuses[0].LastJob == nil {
+		t.Errorf("Status not as expected. %+v\n", statuses)
+	}
+}
+
+func TestSchedulerSkipsOverlap(t *testing.T) {
+
+	manager := jobs.NewManager(2, 10)
+
+	defer manager.Close()
+
+	scheduler := New(manager)
+
+	release := make(chan struct{})
+
+	var runs int32
+
+	scheduler.Add("features", Every(10*time.Millisecond), 0, func(ctx context.Context, reporter *jobs.Reporter) error {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return nil
+	})
+
+	scheduler.Start(context.Background())
+
+	time.Sleep(100 * time.Millisecond)
+
+	scheduler.Stop()
+	close(release)
+
+	status := scheduler.Status()[0]
+
+	if atomic.LoadInt32(&runs) != 1 || status.Runs != 1 || status.Skipped == 0 {
+		t.Errorf("Expected one run and the rest skipped. %d %+v\n", runs, status)
+	}
+}
@@ -0,0 +1,230 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec when a job runs next
+type Spec interface {
+	// Next the first run after the time. Zero when there is none
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// Every runs a job at a fixed interval
+type Every time.Duration
+
+// Next the interval after the time
+func (e Every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+func (e Every) String() string {
+	return "@every " + time.Duration(e).String()
+}
+
+// Cron runs a job at the times matching a five field cron expression:
+// minute hour day-of-month month day-of-week
+type Cron struct {
+	expression string
+	minute     uint64
+	hour       uint64
+	dom        uint64
+	month      uint64
+	dow        uint64
+	// domAll and dowAll the day fields were *. When both are restricted either may match
+	domAll bool
+	dowAll bool
+}
+
+// the shorthand expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field the bounds of a cron field
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var (
+	minuteField = field{"minute", 0, 59}
+	hourField   = field{"hour", 0, 23}
+	domField    = field{"day of month", 1, 31}
+	monthField  = field{"month", 1, 12}
+	dowField    = field{"day of week", 0, 7}
+)
+
+// Parse a cron expression, a descriptor such as @daily, or @every followed by a duration
+func Parse(expression string) (Spec, error) {
+
+	expression = strings.TrimSpace(expression)
+
+	if strings.HasPrefix(expression, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expression, "@every ")))
+
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("Invalid interval %q", expression)
+		}
+
+		return Every(interval), nil
+	}
+
+	fields := expression
+
+	if descriptor, ok := descriptors[expression]; ok {
+		fields = descriptor
+	}
+
+	parts := strings.Fields(fields)
+
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("Expected 5 fields in %q", expression)
+	}
+
+	cron := Cron{expression: expression}
+
+	var err error
+
+	bits := []*uint64{&cron.minute, &cron.hour, &cron.dom, &cron.month, &cron.dow}
+
+	for i, f := range []field{minuteField, hourField, domField, monthField, dowField} {
+		if *bits[i], err = parseField(parts[i], f); err != nil {
+			return nil, fmt.Errorf("Invalid %s in %q: %s", f.name, expression, err)
+		}
+	}
+
+	// 7 is Sunday too
+	if cron.dow&(1<<7) != 0 {
+		cron.dow |= 1
+	}
+
+	cron.domAll = parts[2] == "*"
+	cron.dowAll = parts[4] == "*"
+
+	return cron, nil
+}
+
+// parseField a comma separated list of *, values and ranges, each with an optional /step
+func parseField(value string, f field) (uint64, error) {
+
+	var bits uint64
+
+	for _, item := range strings.Split(value, ",") {
+
+		step := 1
+
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step %q", item)
+			}
+
+			step = n
+			item = item[:i]
+		}
+
+		low, high := f.min, f.max
+
+		if item != "*" {
+			var err error
+
+			bounds := strings.SplitN(item, "-", 2)
+
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("bad value %q", item)
+			}
+
+			high = low
+
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("bad range %q", item)
+				}
+			} else if step > 1 {
+				// 5/15 runs from 5 to the end
+				high = f.max
+			}
+		}
+
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%q is outside %d-%d", item, f.min, f.max)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// dayMatches the day of month and day of week fields, either of them when both are restricted
+func (c Cron) dayMatches(t time.Time) bool {
+
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+
+	if c.domAll || c.dowAll {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// Next the first minute after the time matching the expression, in the time's location
+func (c Cron) Next(after time.Time) time.Time {
+
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	loc := t.Location()
+
+	// an expression such as 0 0 30 2 * never matches
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+
+		if !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !has(c.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c Cron) String() string {
+	return c.expression
+}
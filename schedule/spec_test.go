@@ -0,0 +1,65 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+
+	good := []string{"* * * * *", "*/15 * * * *", "0 3 * * 1-5", "5,35 */2 1 1,6 *", "@daily", "@every 90s", "0 0 * * 7"}
+
+	for _, expression := range good {
+		if _, err := Parse(expression); err != nil {
+			t.Errorf("Expected %q to parse. %+v\n", expression, err)
+		}
+	}
+
+	bad := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@every", "@every -5m", "@fortnightly"}
+
+	for _, expression := range bad {
+		if _, err := Parse(expression); err == nil {
+			t.Errorf("Expected %q not to parse\n", expression)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+
+	// a Sunday
+	from := time.Date(2026, 10, 18, 10, 56, 30, 0, time.UTC)
+
+	cases := []struct {
+		expression string
+		next       time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 18, 10, 57, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		// either day field matches when both are restricted
+		{"0 0 25 * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 10m", time.Date(2026, 10, 18, 11, 6, 30, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		spec, err := Parse(c.expression)
+
+		if err != nil {
+			t.Fatalf("Parsing %q failed. %+v\n", c.expression, err)
+		}
+
+		if next := spec.Next(from); !next.Equal(c.next) {
+			t.Errorf("Next for %q not as expected. %s\n", c.expression, next)
+		}
+	}
+
+	never, _ := Parse("0 0 30 2 *")
+
+	if next := never.Next(from); !next.IsZero() {
+		t.Errorf("February 30th should never come. %s\n", next)
+	}
+}
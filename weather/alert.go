@@ -0,0 +1,325 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Shape an alert area. Polygons are stored as multipolygon coordinates so a Polygon has one entry. Other
+// geometry types are kept as they came and have no polygons
+type Shape struct {
+	Type     string
+	Polygons [][][][]float64
+	raw      json.RawMessage
+}
+
+// UnmarshalJSON parse a GeoJSON Polygon or MultiPolygon, any other geometry is kept raw
+func (s *Shape) UnmarshalJSON(data []byte) error {
+
+	var raw struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+
+	err := json.Unmarshal(data, &raw)
+
+	if err != nil {
+		return err
+	}
+
+	s.Type = raw.Type
+	s.Polygons = nil
+	s.raw = nil
+
+	switch raw.Type {
+	case "Polygon":
+		var polygon [][][]float64
+
+		if err = json.Unmarshal(raw.Coordinates, &polygon); err != nil {
+			return err
+		}
+
+		s.Polygons = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err = json.Unmarshal(raw.Coordinates, &s.Polygons); err != nil {
+			return err
+		}
+	default:
+		// the point test skips it, but the geometry is not lost
+		s.raw = append(json.RawMessage(nil), data...)
+	}
+
+	return nil
+}
+
+// MarshalJSON write the shape back as GeoJSON
+func (s Shape) MarshalJSON() ([]byte, error) {
+
+	if s.raw != nil {
+		return s.raw, nil
+	}
+
+	var coordinates interface{} = s.Polygons
+
+	if s.Type == "Polygon" && len(s.Polygons) == 1 {
+		coordinates = s.Polygons[0]
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"type":        s.Type,
+		"coordinates": coordinates,
+	})
+}
+
+// Contains the latitude and longitude is inside the shape. Holes are honored
+func (s *Shape) Contains(lat float64, lon float64) bool {
+
+	for _, polygon := range s.Polygons {
+
+		// the first ring is the outer boundary, the rest are holes
+		if len(polygon) == 0 || !ringContains(polygon[0], lon, lat) {
+			continue
+		}
+
+		inHole := false
+
+		for _, hole := range polygon[1:] {
+			if ringContains(hole, lon, lat) {
+				inHole = true
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ringContains ray casting test of the [longitude, latitude] ring
+func ringContains(ring [][]float64, x float64, y float64) bool {
+
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		if len(ring[i]) < 2 || len(ring[j]) < 2 {
+			continue
+		}
+
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Geocode the zone codes affected by an alert
+type Geocode struct {
+	SAME []string `json:"SAME"`
+	UGC  []string `json:"UGC"`
+}
+
+// AlertProperties the alert
+type AlertProperties struct {
+	ID            string     `json:"@id"`
+	Type          string     `json:"@type"`
+	AlertID       string     `json:"id"`
+	AreaDesc      string     `json:"areaDesc"`
+	Geocode       Geocode    `json:"geocode"`
+	AffectedZones []string   `json:"affectedZones"`
+	Sent          time.Time  `json:"sent"`
+	Effective     time.Time  `json:"effective"`
+	Onset         *time.Time `json:"onset"`
+	Expires       time.Time  `json:"expires"`
+	Ends          *time.Time `json:"ends"`
+	Status        string     `json:"status"`
+	MessageType   string     `json:"messageType"`
+	Category      string     `json:"category"`
+	Severity      string     `json:"severity"`
+	Certainty     string     `json:"certainty"`
+	Urgency       string     `json:"urgency"`
+	Event         string     `json:"event"`
+	Sender        string     `json:"sender"`
+	SenderName    string     `json:"senderName"`
+	Headline      string     `json:"headline"`
+	Description   string     `json:"description"`
+	Instruction   string     `json:"instruction"`
+	Response      string     `json:"response"`
+}
+
+// Alert a weather alert. Geo is nil when the alert only lists affected zones
+type Alert struct {
+	ID    string          `json:"id"`
+	Type  string          `json:"type"`
+	Geo   *Shape          `json:"geometry"`
+	Props AlertProperties `json:"properties"`
+}
+
+// AlertCollection the alerts returned by the weather service
+type AlertCollection struct {
+	Type    string    `json:"type"`
+	Title   string    `json:"title"`
+	Updated time.Time `json:"updated"`
+	Alerts  []Alert   `json:"features"`
+}
+
+// States the two letter state or marine area codes from the alert's UGC zones
+func (a Alert) States() []string {
+
+	seen := make(map[string]bool)
+	var states []string
+
+	for _, ugc := range a.Props.Geocode.UGC {
+		if len(ugc) < 2 {
+			continue
+		}
+
+		state := strings.ToUpper(ugc[:2])
+
+		if !seen[state] {
+			seen[state] = true
+			states = append(states, state)
+		}
+	}
+
+	sort.Strings(states)
+
+	return states
+}
+
+// Contains the latitude and longitude is inside the alert's area
+func (a Alert) Contains(lat float64, lon float64) bool {
+	return a.Geo != nil && a.Geo.Contains(lat, lon)
+}
+
+// ParseAlerts function
+func ParseAlerts(jayson string) ([]Alert, error) {
+
+	var alerts AlertCollection
+
+	err := json.Unmarshal([]byte(jayson), &alerts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return alerts.Alerts, nil
+}
+
+// GetActiveAlerts all active alerts
+func GetActiveAlerts() ([]Alert, error) {
+	return DefaultClient.GetActiveAlerts()
+}
+
+// GetActiveAlertsContext all active alerts
+func GetActiveAlertsContext(ctx context.Context) ([]Alert, error) {
+	return DefaultClient.GetActiveAlertsContext(ctx)
+}
+
+// GetActiveAlertsByArea the active alerts for a state or marine area, e.g. MD
+func GetActiveAlertsByArea(area string) ([]Alert, error) {
+	return DefaultClient.GetActiveAlertsByArea(area)
+}
+
+// GetActiveAlertsByAreaContext the active alerts for a state or marine area, e.g. MD
+func GetActiveAlertsByAreaContext(ctx context.Context, area string) ([]Alert, error) {
+	return DefaultClient.GetActiveAlertsByAreaContext(ctx, area)
+}
+
+// GetActiveAlertsByZone the active alerts for a zone, e.g. MDZ011
+func GetActiveAlertsByZone(zoneID string) ([]Alert, error) {
+	return DefaultClient.GetActiveAlertsByZone(zoneID)
+}
+
+// GetActiveAlertsByZoneContext the active alerts for a zone, e.g. MDZ011
+func GetActiveAlertsByZoneContext(ctx context.Context, zoneID string) ([]Alert, error) {
+	return DefaultClient.GetActiveAlertsByZoneContext(ctx, zoneID)
+}
+
+// GetActiveAlertsByPoint the active alerts for the latitude and longitude
+func GetActiveAlertsByPoint(lat float64, lon float64) ([]Alert, error) {
+	return DefaultClient.GetActiveAlertsByPoint(lat, lon)
+}
+
+// GetActiveAlertsByPointContext the active alerts for the latitude and longitude
+func GetActiveAlertsByPointContext(ctx context.Context, lat float64, lon float64) ([]Alert, error) {
+	return DefaultClient.GetActiveAlertsByPointContext(ctx, lat, lon)
+}
+
+// GetActiveAlerts wraps GetActiveAlertsContext using context.Background
+func (c *Client) GetActiveAlerts() ([]Alert, error) {
+	return c.GetActiveAlertsContext(context.Background())
+}
+
+// GetActiveAlertsContext all active alerts
+func (c *Client) GetActiveAlertsContext(ctx context.Context) ([]Alert, error) {
+	return c.getAlerts(ctx, "/alerts/active", nil)
+}
+
+// GetActiveAlertsByArea wraps GetActiveAlertsByAreaContext using context.Background
+func (c *Client) GetActiveAlertsByArea(area string) ([]Alert, error) {
+	return c.GetActiveAlertsByAreaContext(context.Background(), area)
+}
+
+// GetActiveAlertsByAreaContext the active alerts for a state or marine area, e.g. MD
+func (c *Client) GetActiveAlertsByAreaContext(ctx context.Context, area string) ([]Alert, error) {
+	return c.getAlerts(ctx, "/alerts/active", map[string]string{"area": strings.ToUpper(area)})
+}
+
+// GetActiveAlertsByZone wraps GetActiveAlertsByZoneContext using context.Background
+func (c *Client) GetActiveAlertsByZone(zoneID string) ([]Alert, error) {
+	return c.GetActiveAlertsByZoneContext(context.Background(), zoneID)
+}
+
+// GetActiveAlertsByZoneContext the active alerts for a zone, e.g. MDZ011
+func (c *Client) GetActiveAlertsByZoneContext(ctx context.Context, zoneID string) ([]Alert, error) {
+	return c.getAlerts(ctx, fmt.Sprintf("/alerts/active/zone/%s", strings.ToUpper(zoneID)), nil)
+}
+
+// GetActiveAlertsByPoint wraps GetActiveAlertsByPointContext using context.Background
+func (c *Client) GetActiveAlertsByPoint(lat float64, lon float64) ([]Alert, error) {
+	return c.GetActiveAlertsByPointContext(context.Background(), lat, lon)
+}
+
+// GetActiveAlertsByPointContext the active alerts for the latitude and longitude
+func (c *Client) GetActiveAlertsByPointContext(ctx context.Context, lat float64, lon float64) ([]Alert, error) {
+	point := strings.TrimPrefix(pointPath(lat, lon), "/points/")
+
+	return c.getAlerts(ctx, "/alerts/active", map[string]string{"point": point})
+}
+
+func (c *Client) getAlerts(ctx context.Context, path string, params map[string]string) ([]Alert, error) {
+
+	resp, err := c.rest.R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		Get(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	alerts, err := ParseAlerts(resp.String())
+
+	if err != nil {
+		log.Printf("Failed unmarshalling into alerts %s", err)
+		return nil, err
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,143 @@
+package weather
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const activeAlerts = `{
+	"type": "FeatureCollection",
+	"title": "Current watches, warnings, and advisories",
+	"features": [
+		{
+			"id": "https://api.weather.gov/alerts/urn:oid:2.49.0.1.840.0.1",
+			"type": "Feature",
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[-77.0, 39.0], [-76.0, 39.0], [-76.0, 40.0], [-77.0, 40.0], [-77.0, 39.0]],
+					[[-76.6, 39.4], [-76.4, 39.4], [-76.4, 39.6], [-76.6, 39.6], [-76.6, 39.4]]]
+			},
+			"properties": {
+				"id": "urn:oid:2.49.0.1.840.0.1",
+				"areaDesc": "Baltimore; Harford",
+				"geocode": {"SAME": ["024005", "024025"], "UGC": ["MDZ011", "MDZ012", "PAZ070"]},
+				"sent": "2026-10-18T10:00:00-04:00",
+				"effective": "2026-10-18T10:00:00-04:00",
+				"onset": null,
+				"expires": "2026-10-18T18:00:00-04:00",
+				"severity": "Severe",
+				"certainty": "Likely",
+				"urgency": "Expected",
+				"event": "Flood Warning"
+			}
+		},
+		{
+			"id": "https://api.weather.gov/alerts/urn:oid:2.49.0.1.840.0.2",
+			"type": "Feature",
+			"geometry": null,
+			"properties": {
+				"id": "urn:oid:2.49.0.1.840.0.2",
+				"geocode": {"UGC": ["CAZ006"]},
+				"onset": "2026-10-18T12:00:00-07:00",
+				"expires": "2026-10-19T12:00:00-07:00",
+				"severity": "Minor",
+				"event": "Wind Advisory"
+			}
+		}
+	]
+}`
+
+func TestParseAlerts(t *testing.T) {
+
+	alerts, err := ParseAlerts(activeAlerts)
+
+	if err != nil {
+		t.Fatalf("Failed parsing the alerts. %+v\n", err)
+	}
+
+	if len(alerts) != 2 {
+		t.Fatalf("Expected 2 alerts. %d\n", len(alerts))
+	}
+
+	flood := alerts[0]
+
+	if flood.Props.Event != "Flood Warning" || flood.Props.Severity != "Severe" || flood.Props.Onset != nil {
+		t.Errorf("Flood warning not as expected. %+v\n", flood.Props)
+	}
+
+	states := flood.States()
+
+	if len(states) != 2 || states[0] != "MD" || states[1] != "PA" {
+		t.Errorf("States not as expected. %v\n", states)
+	}
+
+	if !flood.Contains(39.2, -76.8) {
+		t.Error("Point should be inside the flood warning")
+	}
+
+	if flood.Contains(39.5, -76.5) {
+		t.Error("Point in the hole should not be inside the flood warning")
+	}
+
+	if flood.Contains(41.0, -76.5) {
+		t.Error("Point north of the flood warning should not be inside it")
+	}
+
+	wind := alerts[1]
+
+	if wind.Geo != nil || wind.Contains(38.0, -122.0) {
+		t.Errorf("Wind advisory should have no geometry. %+v\n", wind.Geo)
+	}
+
+	if wind.Props.Onset == nil {
+		t.Error("Wind advisory onset should be set")
+	}
+}
+
+func TestShapeRoundTrip(t *testing.T) {
+
+	var shape Shape
+
+	multi := `{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]], [[[5, 5], [6, 5], [6, 6], [5, 5]]]]}`
+
+	if err := json.Unmarshal([]byte(multi), &shape); err != nil {
+		t.Fatalf("Failed parsing the multipolygon. %+v\n", err)
+	}
+
+	if len(shape.Polygons) != 2 {
+		t.Errorf("Expected 2 polygons. %d\n", len(shape.Polygons))
+	}
+
+	b, err := json.Marshal(shape)
+
+	if err != nil {
+		t.Fatalf("Failed marshalling the multipolygon. %+v\n", err)
+	}
+
+	var again Shape
+
+	if err := json.Unmarshal(b, &again); err != nil || len(again.Polygons) != 2 {
+		t.Errorf("Round trip not as expected. %s %+v\n", b, err)
+	}
+}
+
+func TestShapeUnsupported(t *testing.T) {
+
+	var alert Alert
+
+	point := `{"id": "point", "geometry": {"type": "Point", "coordinates": [-76.5, 39.5]}, "properties": {"event": "Test"}}`
+
+	if err := json.Unmarshal([]byte(point), &alert); err != nil {
+		t.Fatalf("An unsupported geometry should not fail the alert. %+v\n", err)
+	}
+
+	if alert.Geo == nil || alert.Geo.Type != "Point" || alert.Contains(39.5, -76.5) {
+		t.Errorf("Point geometry should be kept without containing anything. %+v\n", alert.Geo)
+	}
+
+	b, err := json.Marshal(alert.Geo)
+
+	if err != nil || string(b) != `{"type":"Point","coordinates":[-76.5,39.5]}` {
+		t.Errorf("Point geometry should be written back as it came. %s %+v\n", b, err)
+	}
+}
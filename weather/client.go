@@ -0,0 +1,125 @@
+package weather
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultBaseURL the National Weather Service API
+const DefaultBaseURL = "https://api.weather.gov"
+
+// DefaultUserAgent sent when no user agent is configured. The NWS API rejects requests without one
+const DefaultUserAgent = "(go-weather, github.com/EdSwArchitect/go-weather)"
+
+// DefaultMaxPages the most pages walked when listing stations
+const DefaultMaxPages = 200
+
+// DefaultPageSize the stations requested per page
+const DefaultPageSize = 500
+
+// DefaultZoneTTL how long a zone outline is kept before it is read again
+const DefaultZoneTTL = 24 * time.Hour
+
+// DefaultMaxZones the most zone outlines kept, the oldest are dropped first
+const DefaultMaxZones = 5000
+
+// ClientConfig the settings for a Client. Zero values fall back to the defaults
+type ClientConfig struct {
+	BaseURL    string
+	UserAgent  string
+	Timeout    time.Duration
+	HTTPClient *http.Client
+	// MaxPages the most pages walked when listing stations
+	MaxPages int
+	// PageSize the stations requested per page
+	PageSize int
+	// ZoneTTL how long a zone outline is kept before it is read again
+	ZoneTTL time.Duration
+	// MaxZones the most zone outlines kept
+	MaxZones int
+}
+
+// Client calls the weather service API
+type Client struct {
+	baseURL  string
+	rest     *resty.Client
+	maxPages int
+	pageSize int
+
+	// zone outlines rarely change, they are kept for zoneTTL
+	zonesMu  sync.Mutex
+	zones    map[string]cachedZone
+	zoneTTL  time.Duration
+	maxZones int
+}
+
+// DefaultClient used by the package level functions
+var DefaultClient = NewClient(ClientConfig{})
+
+// NewClient create a client for the weather service API
+func NewClient(config ClientConfig) *Client {
+
+	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	userAgent := config.UserAgent
+
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	var rest *resty.Client
+
+	if config.HTTPClient != nil {
+		rest = resty.NewWithClient(config.HTTPClient)
+	} else {
+		rest = resty.New()
+	}
+
+	rest.SetHostURL(baseURL)
+	rest.SetHeader("User-Agent", userAgent)
+	rest.SetHeader("Accept", "application/geo+json")
+
+	if config.Timeout > 0 {
+		rest.SetTimeout(config.Timeout)
+	}
+
+	maxPages := config.MaxPages
+
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	pageSize := config.PageSize
+
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	zoneTTL := config.ZoneTTL
+
+	if zoneTTL <= 0 {
+		zoneTTL = DefaultZoneTTL
+	}
+
+	maxZones := config.MaxZones
+
+	if maxZones <= 0 {
+		maxZones = DefaultMaxZones
+	}
+
+	return &Client{baseURL: baseURL, rest: rest, maxPages: maxPages, pageSize: pageSize,
+		zones: make(map[string]cachedZone), zoneTTL: zoneTTL, maxZones: maxZones}
+}
+
+// BaseURL the base URL the client calls
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
@@ -0,0 +1,165 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientBaseURLAndUserAgent(t *testing.T) {
+
+	var userAgent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+
+		if r.URL.Path != "/stations/KSFO" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("content-type", "application/geo+json")
+		fmt.Fprint(w, `{"id":"https://api.weather.gov/stations/KSFO","type":"Feature",
+			"geometry":{"type":"Point","coordinates":[-122.36558,37.61961]},
+			"properties":{"stationIdentifier":"KSFO"}}`)
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{
+		BaseURL:    server.URL + "/",
+		UserAgent:  "(go-weather-test, test@example.com)",
+		Timeout:    5 * time.Second,
+		HTTPClient: server.Client(),
+	})
+
+	feature, err := client.GetFeature("KSFO")
+
+	if err != nil {
+		t.Fatalf("Getting feature from the test server failed: %+v\n", err)
+	}
+
+	if feature.Props.StationID != "KSFO" {
+		t.Errorf("Station ID not as expected. %s\n", feature.Props.StationID)
+	}
+
+	if userAgent != "(go-weather-test, test@example.com)" {
+		t.Errorf("User-Agent not as expected. %s\n", userAgent)
+	}
+
+	_, err = client.GetFeature("Goober")
+
+	if err == nil {
+		t.Error("Found a feature for 'Goober', and that should not be")
+	}
+}
+
+func TestDefaultClient(t *testing.T) {
+
+	if DefaultClient.BaseURL() != DefaultBaseURL {
+		t.Errorf("Default base URL not as expected. %s\n", DefaultClient.BaseURL())
+	}
+}
+
+// pagedStations serves three pages of two stations then an empty page
+func pagedStations() *httptest.Server {
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stations" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		page := 0
+		fmt.Sscanf(r.URL.Query().Get("cursor"), "%d", &page)
+
+		if page >= 3 {
+			fmt.Fprint(w, `{"type": "FeatureCollection", "features": [], "observationStations": []}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"type": "FeatureCollection",
+			"features": [{"id": "%[1]s/stations/S%[2]d0", "type": "Feature"}, {"id": "%[1]s/stations/S%[2]d1", "type": "Feature"}],
+			"observationStations": ["%[1]s/stations/S%[2]d0", "%[1]s/stations/S%[2]d1"],
+			"pagination": {"next": "%[1]s/stations?cursor=%[3]d&limit=2"}}`, server.URL, page, page+1)
+	}))
+
+	return server
+}
+
+func TestStationPagination(t *testing.T) {
+
+	server := pagedStations()
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL, PageSize: 2})
+
+	stations, err := client.GetObservationStations()
+
+	if err != nil {
+		t.Fatalf("Getting stations failed: %+v\n", err)
+	}
+
+	if len(stations.ObservationStations) != 6 {
+		t.Errorf("Expected 6 stations across the pages. %d\n", len(stations.ObservationStations))
+	}
+
+	features, err := client.GetFeatures()
+
+	if err != nil {
+		t.Fatalf("Getting features failed: %+v\n", err)
+	}
+
+	if len(features) != 6 {
+		t.Errorf("Expected 6 features across the pages. %d\n", len(features))
+	}
+
+	limited := NewClient(ClientConfig{BaseURL: server.URL, PageSize: 2, MaxPages: 2})
+
+	stations, err = limited.GetObservationStations()
+
+	if err != nil || len(stations.ObservationStations) != 4 {
+		t.Errorf("Expected 4 stations from 2 pages. %d %+v\n", len(stations.ObservationStations), err)
+	}
+
+	pages := 0
+
+	err = client.EachStationPage(func(page StationPage) error {
+		pages++
+		return ErrStopPaging
+	})
+
+	if err != nil || pages != 1 {
+		t.Errorf("Expected to stop after 1 page. %d %+v\n", pages, err)
+	}
+}
+
+func TestStationPaginationCancelled(t *testing.T) {
+
+	server := pagedStations()
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL, PageSize: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pages := 0
+
+	err := client.EachStationPageContext(ctx, func(page StationPage) error {
+		pages++
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) || pages != 1 {
+		t.Errorf("Expected the cancelled context to stop after 1 page. %d %+v\n", pages, err)
+	}
+}
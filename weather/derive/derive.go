@@ -0,0 +1,225 @@
+// Package derive computes the metrics derived from an observation, such as the heat index and the
+// flight category, with the formulas the National Weather Service uses
+package derive
+
+import (
+	"math"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+// Category a flight category, from the ceiling and visibility
+type Category string
+
+// The flight categories, from the best conditions to the worst
+const (
+	VFR  Category = "VFR"  // ceiling above 3000 feet and visibility above 5 miles
+	MVFR Category = "MVFR" // ceiling 1000 to 3000 feet or visibility 3 to 5 miles
+	IFR  Category = "IFR"  // ceiling 500 to below 1000 feet or visibility 1 to below 3 miles
+	LIFR Category = "LIFR" // ceiling below 500 feet or visibility below 1 mile
+)
+
+// Metrics the metrics derived from an observation. A metric is nil when the measurements it needs were not
+// reported or it does not apply, such as the wind chill on a warm day. Temperatures are degrees Celsius
+type Metrics struct {
+	HeatIndex           *float64 `json:"heatIndex,omitempty"`
+	WindChill           *float64 `json:"windChill,omitempty"`
+	ApparentTemperature *float64 `json:"apparentTemperature,omitempty"`
+	RelativeHumidity    *float64 `json:"relativeHumidity,omitempty"` // percent
+	DewpointDepression  *float64 `json:"dewpointDepression,omitempty"`
+	Ceiling             *float64 `json:"ceiling,omitempty"` // feet, nil when there is no ceiling
+	FlightCategory      Category `json:"flightCategory,omitempty"`
+}
+
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+func fahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// RelativeHumidity the relative humidity, percent, from the temperature and dewpoint using the
+// Magnus formula
+func RelativeHumidity(temperature float64, dewpoint float64) float64 {
+	saturation := func(t float64) float64 {
+		return math.Exp(17.625 * t / (243.04 + t))
+	}
+
+	return math.Min(100, 100*saturation(dewpoint)/saturation(temperature))
+}
+
+// HeatIndex the heat index from the temperature and relative humidity, using the Rothfusz regression
+// and its adjustments. False below 80F, where the heat index does not apply
+func HeatIndex(temperature float64, humidity float64) (float64, bool) {
+
+	t := celsiusToFahrenheit(temperature)
+
+	if t < 80 {
+		return 0, false
+	}
+
+	hi := 0.5 * (t + 61 + (t-68)*1.2 + humidity*0.094)
+
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*humidity - 0.22475541*t*humidity -
+			0.00683783*t*t - 0.05481717*humidity*humidity + 0.00122874*t*t*humidity +
+			0.00085282*t*humidity*humidity - 0.00000199*t*t*humidity*humidity
+
+		if humidity < 13 && t <= 112 {
+			hi -= (13 - humidity) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		}
+
+		if humidity > 85 && t <= 87 {
+			hi += (humidity - 85) / 10 * (87 - t) / 5
+		}
+	}
+
+	return fahrenheitToCelsius(hi), true
+}
+
+// WindChill the wind chill from the temperature and the wind speed in km/h. False above 10C or
+// below a 4.8 km/h wind, where the wind chill does not apply
+func WindChill(temperature float64, windSpeed float64) (float64, bool) {
+
+	if temperature > 10 || windSpeed < 4.8 {
+		return 0, false
+	}
+
+	v := math.Pow(windSpeed, 0.16)
+
+	return 13.12 + 0.6215*temperature - 11.37*v + 0.3965*temperature*v, true
+}
+
+// ApparentTemperature the heat index or wind chill when either applies, otherwise the temperature
+func ApparentTemperature(temperature float64, humidity float64, windSpeed float64) float64 {
+
+	if hi, ok := HeatIndex(temperature, humidity); ok {
+		return hi
+	}
+
+	if wc, ok := WindChill(temperature, windSpeed); ok {
+		return wc
+	}
+
+	return temperature
+}
+
+// FlightCategory the category of the ceiling in feet and the visibility in statute miles. A nil ceiling is
+// an unlimited ceiling and a nil visibility is not considered
+func FlightCategory(ceiling *float64, visibility *float64) Category {
+
+	category := func(c float64, v float64) Category {
+		switch {
+		case c < 500 || v < 1:
+			return LIFR
+		case c < 1000 || v < 3:
+			return IFR
+		case c <= 3000 || v <= 5:
+			return MVFR
+		}
+
+		return VFR
+	}
+
+	c, v := math.Inf(1), math.Inf(1)
+
+	if ceiling != nil {
+		c = *ceiling
+	}
+
+	if visibility != nil {
+		v = *visibility
+	}
+
+	return category(c, v)
+}
+
+// Ceiling the base in feet of the lowest broken, overcast or obscured layer. False when there is none
+func Ceiling(layers []weather.CloudLayer) (float64, bool) {
+
+	ceiling, found := 0.0, false
+
+	for _, layer := range layers {
+		switch layer.Amount {
+		case "BKN", "OVC", "VV":
+		default:
+			continue
+		}
+
+		if base, ok := layer.Base.In(weather.Foot); ok && (!found || base < ceiling) {
+			ceiling, found = base, true
+		}
+	}
+
+	return ceiling, found
+}
+
+// Observation the metrics derived from the observation's measurements
+func Observation(observation weather.Observation) Metrics {
+
+	var metrics Metrics
+
+	p := observation.Props
+
+	temperature, hasTemperature := p.Temperature.In(weather.Celsius)
+	dewpoint, hasDewpoint := p.Dewpoint.In(weather.Celsius)
+	windSpeed, hasWind := p.WindSpeed.In(weather.KilometersPerHour)
+
+	humidity, hasHumidity := p.RelativeHumidity.In(weather.Percent)
+
+	if hasTemperature && hasDewpoint {
+		humidity, hasHumidity = RelativeHumidity(temperature, dewpoint), true
+		metrics.RelativeHumidity = &humidity
+
+		depression := temperature - dewpoint
+		metrics.DewpointDepression = &depression
+	}
+
+	if hasTemperature && hasHumidity {
+		if hi, ok := HeatIndex(temperature, humidity); ok {
+			metrics.HeatIndex = &hi
+		}
+	}
+
+	if hasTemperature && hasWind {
+		if wc, ok := WindChill(temperature, windSpeed); ok {
+			metrics.WindChill = &wc
+		}
+	}
+
+	// the apparent temperature needs what the heat index or wind chill would need
+	if hasTemperature && (hasHumidity || hasWind) {
+		apparent := temperature
+
+		switch {
+		case metrics.HeatIndex != nil:
+			apparent = *metrics.HeatIndex
+		case metrics.WindChill != nil:
+			apparent = *metrics.WindChill
+		}
+
+		metrics.ApparentTemperature = &apparent
+	}
+
+	ceiling, hasCeiling := Ceiling(p.CloudLayers)
+
+	if hasCeiling {
+		metrics.Ceiling = &ceiling
+	}
+
+	visibility, hasVisibility := p.Visibility.In(weather.Mile)
+
+	// without either the category is unknown
+	if hasVisibility || len(p.CloudLayers) > 0 {
+		var v *float64
+
+		if hasVisibility {
+			v = &visibility
+		}
+
+		metrics.FlightCategory = FlightCategory(metrics.Ceiling, v)
+	}
+
+	return metrics
+}
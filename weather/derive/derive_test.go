@@ -0,0 +1,126 @@
+package derive
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EdSwArchitect/go-weather/weather"
+)
+
+func near(a float64, b float64, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestHeatIndex(t *testing.T) {
+
+	// the NWS heat index chart, 96F at 65% is 121F
+	if hi, ok := HeatIndex(fahrenheitToCelsius(96), 65); !ok || !near(celsiusToFahrenheit(hi), 121, 1) {
+		t.Errorf("Heat index not as expected. %f %v\n", celsiusToFahrenheit(hi), ok)
+	}
+
+	// 90F at 40% is 91F
+	if hi, _ := HeatIndex(fahrenheitToCelsius(90), 40); !near(celsiusToFahrenheit(hi), 91, 1) {
+		t.Errorf("Heat index not as expected. %f\n", celsiusToFahrenheit(hi))
+	}
+
+	if _, ok := HeatIndex(20, 90); ok {
+		t.Errorf("The heat index does not apply at 20C\n")
+	}
+}
+
+func TestWindChill(t *testing.T) {
+
+	// the NWS wind chill chart, 0F with a 15 mph wind is -19F
+	if wc, ok := WindChill(fahrenheitToCelsius(0), 15*1.609344); !ok || !near(celsiusToFahrenheit(wc), -19, 0.5) {
+		t.Errorf("Wind chill not as expected. %f %v\n", celsiusToFahrenheit(wc), ok)
+	}
+
+	if _, ok := WindChill(15, 30); ok {
+		t.Errorf("The wind chill does not apply at 15C\n")
+	}
+
+	if _, ok := WindChill(-5, 2); ok {
+		t.Errorf("The wind chill does not apply in calm wind\n")
+	}
+
+	if at := ApparentTemperature(15, 50, 30); at != 15 {
+		t.Errorf("Apparent temperature should be the temperature. %f\n", at)
+	}
+}
+
+func TestRelativeHumidity(t *testing.T) {
+
+	if rh := RelativeHumidity(14, 11); !near(rh, 82, 0.5) {
+		t.Errorf("Relative humidity not as expected. %f\n", rh)
+	}
+
+	if rh := RelativeHumidity(10, 10); rh != 100 {
+		t.Errorf("Relative humidity at the dewpoint should be 100. %f\n", rh)
+	}
+}
+
+func TestFlightCategory(t *testing.T) {
+
+	value := func(v float64) *float64 {
+		return &v
+	}
+
+	cases := []struct {
+		ceiling    *float64
+		visibility *float64
+		category   Category
+	}{
+		{nil, value(10), VFR},
+		{value(3500), value(6), VFR},
+		{value(3000), value(10), MVFR},
+		{nil, value(4), MVFR},
+		{value(800), value(10), IFR},
+		{value(5000), value(2), IFR},
+		{value(300), value(10), LIFR},
+		{nil, value(0.5), LIFR},
+		{nil, nil, VFR},
+	}
+
+	for _, c := range cases {
+		if category := FlightCategory(c.ceiling, c.visibility); category != c.category {
+			t.Errorf("Flight category of %v %v not as expected. %s\n", c.ceiling, c.visibility, category)
+		}
+	}
+}
+
+func TestObservation(t *testing.T) {
+
+	var observation weather.Observation
+
+	p := &observation.Props
+	p.Temperature = weather.NewQuantity(14, weather.Celsius)
+	p.Dewpoint = weather.NewQuantity(11, weather.Celsius)
+	p.WindSpeed = weather.NewQuantity(22.224, weather.KilometersPerHour)
+	p.Visibility = weather.NewQuantity(16090, weather.Meter)
+	p.CloudLayers = []weather.CloudLayer{
+		{Amount: "FEW", Base: weather.NewQuantity(240, weather.Meter)},
+		{Amount: "BKN", Base: weather.NewQuantity(610, weather.Meter)},
+	}
+
+	metrics := Observation(observation)
+
+	if metrics.RelativeHumidity == nil || !near(*metrics.RelativeHumidity, 82, 0.5) {
+		t.Errorf("Relative humidity not as expected. %+v\n", metrics.RelativeHumidity)
+	}
+
+	if metrics.DewpointDepression == nil || *metrics.DewpointDepression != 3 {
+		t.Errorf("Dewpoint depression not as expected. %+v\n", metrics.DewpointDepression)
+	}
+
+	if metrics.HeatIndex != nil || metrics.WindChill != nil || metrics.ApparentTemperature == nil || *metrics.ApparentTemperature != 14 {
+		t.Errorf("Expected only the apparent temperature on a mild day. %+v\n", metrics)
+	}
+
+	if metrics.Ceiling == nil || !near(*metrics.Ceiling, 2001, 1) || metrics.FlightCategory != MVFR {
+		t.Errorf("Ceiling and flight category not as expected. %+v %s\n", metrics.Ceiling, metrics.FlightCategory)
+	}
+
+	if empty := Observation(weather.Observation{}); empty != (Metrics{}) {
+		t.Errorf("Expected no metrics without measurements. %+v\n", empty)
+	}
+}
@@ -0,0 +1,106 @@
+package weather
+
+import (
+	"github.com/EdSwArchitect/go-weather/weather/metar"
+)
+
+// metarWindUnits the unit of each METAR wind speed unit
+var metarWindUnits = map[string]Unit{
+	"KT":  Knot,
+	"MPS": MetersPerSecond,
+	"KMH": KilometersPerHour,
+}
+
+// fill set the quantity from the METAR value, in the weather service's unit, when it was not reported
+func fill(q *Quantity, value *float64, from Unit, to Unit) {
+	if q.Value != nil || value == nil {
+		return
+	}
+
+	converted, err := NewQuantity(*value, from).Convert(to)
+
+	if err != nil {
+		return
+	}
+
+	*q = converted
+}
+
+// Enrich fill the measurements the station did not report from the decoded raw message. The observation
+// is returned unchanged when there is no raw message or it does not decode
+func (o Observation) Enrich() Observation {
+
+	if o.Props.RawMessage == "" {
+		return o
+	}
+
+	report, err := metar.Parse(o.Props.RawMessage)
+
+	if err != nil || report.Missing {
+		return o
+	}
+
+	p := &o.Props
+
+	// the remarks have the temperatures in tenths
+	temperature, dewpoint := report.Temperature, report.Dewpoint
+
+	if report.Remarks.Temperature != nil {
+		temperature = report.Remarks.Temperature
+	}
+
+	if report.Remarks.Dewpoint != nil {
+		dewpoint = report.Remarks.Dewpoint
+	}
+
+	fill(&p.Temperature, temperature, Celsius, Celsius)
+	fill(&p.Dewpoint, dewpoint, Celsius, Celsius)
+
+	if wind := report.Wind; wind != nil {
+		if unit, ok := metarWindUnits[wind.Unit]; ok {
+			speed := float64(wind.Speed)
+			fill(&p.WindSpeed, &speed, unit, KilometersPerHour)
+
+			if wind.Gust != nil {
+				gust := float64(*wind.Gust)
+				fill(&p.WindGust, &gust, unit, KilometersPerHour)
+			}
+		}
+
+		if wind.Direction != nil {
+			direction := float64(*wind.Direction)
+			fill(&p.WindDirection, &direction, Degree, Degree)
+		}
+	}
+
+	if report.Altimeter != nil {
+		pressure := report.Altimeter.Hectopascals()
+		fill(&p.BarometricPressure, &pressure, Hectopascal, Pascal)
+	}
+
+	fill(&p.SeaLevelPressure, report.Remarks.SeaLevelPressure, Hectopascal, Pascal)
+
+	if report.Visibility != nil {
+		meters := report.Visibility.Meters()
+		fill(&p.Visibility, &meters, Meter, Meter)
+	}
+
+	fill(&p.PrecipitationLastHour, report.Remarks.PrecipitationLastHour, Inch, Millimeter)
+	fill(&p.MaxTemperatureLast24Hours, report.Remarks.MaxTemperature24Hours, Celsius, Celsius)
+	fill(&p.MinTemperatureLast24Hours, report.Remarks.MinTemperature24Hours, Celsius, Celsius)
+
+	if len(p.CloudLayers) == 0 {
+		for _, cloud := range report.Clouds {
+			layer := CloudLayer{Amount: cloud.Cover, Base: Quantity{UnitCode: Meter.Code()}}
+
+			if cloud.Base != nil {
+				feet := float64(*cloud.Base)
+				fill(&layer.Base, &feet, Foot, Meter)
+			}
+
+			p.CloudLayers = append(p.CloudLayers, layer)
+		}
+	}
+
+	return o
+}
@@ -0,0 +1,79 @@
+package weather
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestEnrich(t *testing.T) {
+
+	var observation Observation
+
+	if err := json.Unmarshal([]byte(`{
+		"id": "https://api.weather.gov/stations/KBOS/observations/2026-10-18T10:54:00+00:00",
+		"properties": {
+			"rawMessage": "KBOS 181054Z 04018G28KT 3/4SM +RA BR BKN006 OVC012 11/10 A2961 RMK AO2 SLP029 P0021 T01110100",
+			"temperature": {"unitCode": "wmoUnit:degC", "value": 11.1, "qualityControl": "V"},
+			"dewpoint": {"unitCode": "wmoUnit:degC", "value": null, "qualityControl": "Z"},
+			"windSpeed": {"unitCode": "wmoUnit:km_h-1", "value": null, "qualityControl": "Z"},
+			"windGust": {"unitCode": "wmoUnit:km_h-1", "value": null, "qualityControl": "Z"},
+			"seaLevelPressure": {"unitCode": "wmoUnit:Pa", "value": null, "qualityControl": "Z"},
+			"precipitationLastHour": {"unitCode": "wmoUnit:mm", "value": null, "qualityControl": "Z"},
+			"cloudLayers": []
+		}
+	}`), &observation); err != nil {
+		t.Fatalf("Unmarshalling the observation failed. %+v\n", err)
+	}
+
+	enriched := observation.Enrich()
+	p := enriched.Props
+
+	if p.Temperature.QualityControl != "V" || *p.Temperature.Value != 11.1 {
+		t.Errorf("A reported temperature should not change. %+v\n", p.Temperature)
+	}
+
+	if v, ok := p.Dewpoint.In(Celsius); !ok || v != 10 {
+		t.Errorf("Dewpoint not filled from the remarks. %+v\n", p.Dewpoint)
+	}
+
+	if v, ok := p.WindSpeed.In(Knot); !ok || math.Abs(v-18) > 0.001 || p.WindSpeed.UnitCode != "wmoUnit:km_h-1" {
+		t.Errorf("Wind speed not filled in km/h. %+v\n", p.WindSpeed)
+	}
+
+	if v, ok := p.WindGust.In(Knot); !ok || math.Abs(v-28) > 0.001 {
+		t.Errorf("Wind gust not filled. %+v\n", p.WindGust)
+	}
+
+	if v, ok := p.WindDirection.In(Degree); !ok || v != 40 {
+		t.Errorf("Wind direction not filled. %+v\n", p.WindDirection)
+	}
+
+	if v, ok := p.SeaLevelPressure.In(Pascal); !ok || math.Abs(v-100290) > 0.001 {
+		t.Errorf("Sea level pressure not filled. %+v\n", p.SeaLevelPressure)
+	}
+
+	if v, ok := p.PrecipitationLastHour.In(Millimeter); !ok || math.Abs(v-5.334) > 0.001 {
+		t.Errorf("Precipitation not filled. %+v\n", p.PrecipitationLastHour)
+	}
+
+	if v, ok := p.Visibility.In(Mile); !ok || math.Abs(v-0.75) > 0.001 {
+		t.Errorf("Visibility not filled. %+v\n", p.Visibility)
+	}
+
+	if len(p.CloudLayers) != 2 || p.CloudLayers[1].Amount != "OVC" {
+		t.Fatalf("Cloud layers not filled. %+v\n", p.CloudLayers)
+	}
+
+	if v, _ := p.CloudLayers[0].Base.In(Foot); math.Abs(v-600) > 0.001 {
+		t.Errorf("Cloud base not as expected. %+v\n", p.CloudLayers[0].Base)
+	}
+
+	if observation.Props.Dewpoint.Value != nil {
+		t.Errorf("Enriching changed the original observation. %+v\n", observation.Props.Dewpoint)
+	}
+
+	if unchanged := (Observation{}).Enrich(); unchanged.Props.Temperature.Value != nil {
+		t.Errorf("An observation without a raw message should not change. %+v\n", unchanged)
+	}
+}
@@ -0,0 +1,260 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PointProperties the forecast office and grid for a point
+type PointProperties struct {
+	ID                  string `json:"@id"`
+	Type                string `json:"@type"`
+	GridID              string `json:"gridId"`
+	GridX               int    `json:"gridX"`
+	GridY               int    `json:"gridY"`
+	Forecast            string `json:"forecast"`
+	ForecastHourly      string `json:"forecastHourly"`
+	ForecastGridData    string `json:"forecastGridData"`
+	ObservationStations string `json:"observationStations"`
+	ForecastZone        string `json:"forecastZone"`
+	County              string `json:"county"`
+	FireWeatherZone     string `json:"fireWeatherZone"`
+	TimeZone            string `json:"timeZone"`
+	RadarStation        string `json:"radarStation"`
+}
+
+// Point a latitude and longitude resolved by the weather service
+type Point struct {
+	ID    string          `json:"id"`
+	Type  string          `json:"type"`
+	Geo   Geometry        `json:"geometry"`
+	Props PointProperties `json:"properties"`
+}
+
+// ForecastPeriod a forecast for a period of time
+type ForecastPeriod struct {
+	Number                     int       `json:"number"`
+	Name                       string    `json:"name"`
+	StartTime                  time.Time `json:"startTime"`
+	EndTime                    time.Time `json:"endTime"`
+	IsDaytime                  bool      `json:"isDaytime"`
+	Temperature                float64   `json:"temperature"`
+	TemperatureUnit            string    `json:"temperatureUnit"`
+	TemperatureTrend           string    `json:"temperatureTrend"`
+	ProbabilityOfPrecipitation Quantity  `json:"probabilityOfPrecipitation"`
+	WindSpeed                  string    `json:"windSpeed"`
+	WindDirection              string    `json:"windDirection"`
+	Icon                       string    `json:"icon"`
+	ShortForecast              string    `json:"shortForecast"`
+	DetailedForecast           string    `json:"detailedForecast"`
+}
+
+// ForecastProperties the forecast
+type ForecastProperties struct {
+	Updated           time.Time        `json:"updated"`
+	Units             string           `json:"units"`
+	ForecastGenerator string           `json:"forecastGenerator"`
+	GeneratedAt       time.Time        `json:"generatedAt"`
+	UpdateTime        time.Time        `json:"updateTime"`
+	Elevation         Quantity         `json:"elevation"`
+	Periods           []ForecastPeriod `json:"periods"`
+}
+
+// Forecast a gridpoint or zone forecast. The geometry is a polygon, so it is not kept
+type Forecast struct {
+	Type  string             `json:"type"`
+	Props ForecastProperties `json:"properties"`
+}
+
+// pointPath the weather service allows at most four decimal places
+func pointPath(lat float64, lon float64) string {
+	round := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+	}
+
+	return fmt.Sprintf("/points/%s,%s", round(lat), round(lon))
+}
+
+// GetPoint resolve the latitude and longitude
+func GetPoint(lat float64, lon float64) (Point, error) {
+	return DefaultClient.GetPoint(lat, lon)
+}
+
+// GetPointContext resolve the latitude and longitude
+func GetPointContext(ctx context.Context, lat float64, lon float64) (Point, error) {
+	return DefaultClient.GetPointContext(ctx, lat, lon)
+}
+
+// GetForecast the 12 hour period forecast for the latitude and longitude
+func GetForecast(lat float64, lon float64) (Forecast, error) {
+	return DefaultClient.GetForecast(lat, lon)
+}
+
+// GetForecastContext the 12 hour period forecast for the latitude and longitude
+func GetForecastContext(ctx context.Context, lat float64, lon float64) (Forecast, error) {
+	return DefaultClient.GetForecastContext(ctx, lat, lon)
+}
+
+// GetHourlyForecast the hourly forecast for the latitude and longitude
+func GetHourlyForecast(lat float64, lon float64) (Forecast, error) {
+	return DefaultClient.GetHourlyForecast(lat, lon)
+}
+
+// GetHourlyForecastContext the hourly forecast for the latitude and longitude
+func GetHourlyForecastContext(ctx context.Context, lat float64, lon float64) (Forecast, error) {
+	return DefaultClient.GetHourlyForecastContext(ctx, lat, lon)
+}
+
+// GetForecastURL the forecast at the URL
+func GetForecastURL(forecastURL string) (Forecast, error) {
+	return DefaultClient.GetForecastURL(forecastURL)
+}
+
+// GetForecastURLContext the forecast at the URL
+func GetForecastURLContext(ctx context.Context, forecastURL string) (Forecast, error) {
+	return DefaultClient.GetForecastURLContext(ctx, forecastURL)
+}
+
+// GetStationForecast the 12 hour period forecast for the station
+func GetStationForecast(feature Feature) (Forecast, error) {
+	return DefaultClient.GetStationForecast(feature)
+}
+
+// GetStationForecastContext the 12 hour period forecast for the station
+func GetStationForecastContext(ctx context.Context, feature Feature) (Forecast, error) {
+	return DefaultClient.GetStationForecastContext(ctx, feature)
+}
+
+// GetPoint wraps GetPointContext using context.Background
+func (c *Client) GetPoint(lat float64, lon float64) (Point, error) {
+	return c.GetPointContext(context.Background(), lat, lon)
+}
+
+// GetPointContext resolve the latitude and longitude
+func (c *Client) GetPointContext(ctx context.Context, lat float64, lon float64) (Point, error) {
+
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return Point{}, fmt.Errorf("Invalid point %f,%f", lat, lon)
+	}
+
+	resp, err := c.rest.R().SetContext(ctx).Get(pointPath(lat, lon))
+
+	if err != nil {
+		return Point{}, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return Point{}, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	var point Point
+
+	err = json.Unmarshal(resp.Body(), &point)
+
+	if err != nil {
+		log.Printf("Failed unmarshalling into point %s", err)
+		return Point{}, err
+	}
+
+	return point, nil
+}
+
+// GetForecast wraps GetForecastContext using context.Background
+func (c *Client) GetForecast(lat float64, lon float64) (Forecast, error) {
+	return c.GetForecastContext(context.Background(), lat, lon)
+}
+
+// GetForecastContext the 12 hour period forecast for the latitude and longitude
+func (c *Client) GetForecastContext(ctx context.Context, lat float64, lon float64) (Forecast, error) {
+
+	point, err := c.GetPointContext(ctx, lat, lon)
+
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	return c.GetForecastURLContext(ctx, point.Props.Forecast)
+}
+
+// GetHourlyForecast wraps GetHourlyForecastContext using context.Background
+func (c *Client) GetHourlyForecast(lat float64, lon float64) (Forecast, error) {
+	return c.GetHourlyForecastContext(context.Background(), lat, lon)
+}
+
+// GetHourlyForecastContext the hourly forecast for the latitude and longitude
+func (c *Client) GetHourlyForecastContext(ctx context.Context, lat float64, lon float64) (Forecast, error) {
+
+	point, err := c.GetPointContext(ctx, lat, lon)
+
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	return c.GetForecastURLContext(ctx, point.Props.ForecastHourly)
+}
+
+// GetStationForecast wraps GetStationForecastContext using context.Background
+func (c *Client) GetStationForecast(feature Feature) (Forecast, error) {
+	return c.GetStationForecastContext(context.Background(), feature)
+}
+
+// GetStationForecastContext the 12 hour period forecast for the station's location. The forecast URL of
+// a station is its forecast zone, which has no periods, so a station without a location gets the zone's
+// forecast instead
+func (c *Client) GetStationForecastContext(ctx context.Context, feature Feature) (Forecast, error) {
+
+	if coordinates := feature.Geo.Coordinates; len(coordinates) >= 2 {
+		return c.GetForecastContext(ctx, coordinates[1], coordinates[0])
+	}
+
+	if feature.Props.Forecast == "" {
+		return Forecast{}, fmt.Errorf("Station %s has neither a location nor a forecast zone", feature.StationID())
+	}
+
+	return c.GetForecastURLContext(ctx, strings.TrimSuffix(feature.Props.Forecast, "/")+"/forecast")
+}
+
+// GetForecastURL wraps GetForecastURLContext using context.Background
+func (c *Client) GetForecastURL(forecastURL string) (Forecast, error) {
+	return c.GetForecastURLContext(context.Background(), forecastURL)
+}
+
+// GetForecastURLContext the forecast at the URL. The URL is either absolute, as returned by the weather
+// service, or a path relative to the base URL
+func (c *Client) GetForecastURLContext(ctx context.Context, forecastURL string) (Forecast, error) {
+
+	if forecastURL == "" {
+		return Forecast{}, fmt.Errorf("No forecast URL given")
+	}
+
+	resp, err := c.rest.R().SetContext(ctx).Get(forecastURL)
+
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return Forecast{}, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	var forecast Forecast
+
+	err = json.Unmarshal(resp.Body(), &forecast)
+
+	if err != nil {
+		log.Printf("Failed unmarshalling into forecast %s", err)
+		return Forecast{}, err
+	}
+
+	if len(forecast.Props.Periods) == 0 {
+		return Forecast{}, fmt.Errorf("No forecast periods at %s", forecastURL)
+	}
+
+	return forecast, nil
+}
@@ -0,0 +1,136 @@
+package weather
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPointPath(t *testing.T) {
+
+	path := pointPath(39.745612, -97.08921)
+
+	if path != "/points/39.7456,-97.0892" {
+		t.Errorf("Point path not as expected. %s\n", path)
+	}
+}
+
+func TestGetForecast(t *testing.T) {
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/points/39.7456,-97.0892":
+			fmt.Fprintf(w, `{"id": "%[1]s/points/39.7456,-97.0892", "type": "Feature",
+				"geometry": {"type": "Point", "coordinates": [-97.0892, 39.7456]},
+				"properties": {"gridId": "TOP", "gridX": 32, "gridY": 81,
+				"forecast": "%[1]s/gridpoints/TOP/32,81/forecast",
+				"forecastHourly": "%[1]s/gridpoints/TOP/32,81/forecast/hourly"}}`, server.URL)
+		case "/gridpoints/TOP/32,81/forecast", "/gridpoints/TOP/32,81/forecast/hourly":
+			fmt.Fprint(w, `{"type": "Feature",
+				"geometry": {"type": "Polygon", "coordinates": [[[-97.1, 39.7], [-97.1, 39.8], [-97.0, 39.8], [-97.1, 39.7]]]},
+				"properties": {"units": "us", "periods": [
+				{"number": 1, "name": "Today", "startTime": "2026-10-18T06:00:00-05:00",
+				"endTime": "2026-10-18T18:00:00-05:00", "isDaytime": true, "temperature": 71,
+				"temperatureUnit": "F", "probabilityOfPrecipitation": {"unitCode": "wmoUnit:percent", "value": null},
+				"windSpeed": "5 to 10 mph", "windDirection": "S", "shortForecast": "Sunny"}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	forecast, err := client.GetForecast(39.745612, -97.08921)
+
+	if err != nil {
+		t.Fatalf("Getting forecast failed: %+v\n", err)
+	}
+
+	if len(forecast.Props.Periods) != 1 {
+		t.Fatalf("Expected 1 forecast period. %d\n", len(forecast.Props.Periods))
+	}
+
+	period := forecast.Props.Periods[0]
+
+	if period.Name != "Today" || period.Temperature != 71 || period.TemperatureUnit != "F" {
+		t.Errorf("Forecast period not as expected. %+v\n", period)
+	}
+
+	_, err = client.GetHourlyForecast(39.745612, -97.08921)
+
+	if err != nil {
+		t.Errorf("Getting hourly forecast failed: %+v\n", err)
+	}
+
+	_, err = client.GetStationForecast(Feature{})
+
+	if err == nil {
+		t.Error("Following an empty forecast URL should fail")
+	}
+}
+
+func TestGetStationForecast(t *testing.T) {
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/points/37.6196,-122.3656":
+			fmt.Fprintf(w, `{"properties": {"gridId": "MTR", "gridX": 89, "gridY": 98,
+				"forecast": "%s/gridpoints/MTR/89,98/forecast"}}`, server.URL)
+		case "/gridpoints/MTR/89,98/forecast":
+			fmt.Fprint(w, `{"type": "Feature", "properties": {"units": "us", "periods": [
+				{"number": 1, "name": "Today", "temperature": 68, "temperatureUnit": "F", "windSpeed": "10 mph"}]}}`)
+		case "/zones/forecast/CAZ508":
+			// the zone itself, as a station's forecast URL returns it, has no periods
+			fmt.Fprint(w, `{"id": "https://api.weather.gov/zones/forecast/CAZ508", "type": "Feature",
+				"properties": {"id": "CAZ508", "type": "public", "name": "San Francisco Peninsula Coast",
+				"state": "CA", "forecastOffices": ["https://api.weather.gov/offices/MTR"]}}`)
+		case "/zones/forecast/CAZ508/forecast":
+			fmt.Fprint(w, `{"type": "Feature", "properties": {"zone": "https://api.weather.gov/zones/forecast/CAZ508",
+				"periods": [{"number": 1, "name": "Today", "detailedForecast": "Patchy fog in the morning."}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	station, err := ParseWeather(fmt.Sprintf(`{
+		"id": "https://api.weather.gov/stations/KSFO",
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [-122.36558, 37.61961]},
+		"properties": {"stationIdentifier": "KSFO",
+		"forecast": "%[1]s/zones/forecast/CAZ508",
+		"county": "https://api.weather.gov/zones/county/CAC081"}}`, server.URL))
+
+	if err != nil {
+		t.Fatalf("Failed parsing the station. %+v\n", err)
+	}
+
+	forecast, err := client.GetStationForecast(station)
+
+	if err != nil || len(forecast.Props.Periods) != 1 || forecast.Props.Periods[0].Temperature != 68 {
+		t.Errorf("Expected the gridpoint forecast at the station. %+v %+v\n", forecast, err)
+	}
+
+	// without a location the zone's forecast is used
+	station.Geo = Geometry{}
+
+	forecast, err = client.GetStationForecast(station)
+
+	if err != nil || len(forecast.Props.Periods) != 1 || forecast.Props.Periods[0].DetailedForecast != "Patchy fog in the morning." {
+		t.Errorf("Expected the zone forecast. %+v %+v\n", forecast, err)
+	}
+
+	if _, err := client.GetForecastURL(station.Props.Forecast); err == nil {
+		t.Error("A forecast without periods should fail")
+	}
+}
@@ -0,0 +1,38 @@
+package weather
+
+import "encoding/json"
+
+// geoJSONFeature a Feature as RFC 7946 has it, a feature without a location has a null geometry
+type geoJSONFeature struct {
+	ID         string     `json:"id,omitempty"`
+	Type       string     `json:"type"`
+	Geometry   *Geometry  `json:"geometry"`
+	Properties Properties `json:"properties"`
+}
+
+// NewFeatureCollection the features as a feature collection
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// MarshalJSON the collection as GeoJSON. Every feature is typed Feature and has a geometry member,
+// which the weather service features only have when they are located. The station URLs are left out
+func (c FeatureCollection) MarshalJSON() ([]byte, error) {
+
+	features := make([]geoJSONFeature, len(c.Features))
+
+	for i, feature := range c.Features {
+		features[i] = geoJSONFeature{ID: feature.ID, Type: "Feature", Properties: feature.Props}
+
+		if feature.Geo.Type != "" && len(feature.Geo.Coordinates) >= 2 {
+			geometry := feature.Geo
+			features[i].Geometry = &geometry
+		}
+	}
+
+	return json.Marshal(struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+		Next     string           `json:"next,omitempty"`
+	}{"FeatureCollection", features, c.Next})
+}
@@ -0,0 +1,61 @@
+package weather
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFeatureCollectionGeoJSON(t *testing.T) {
+
+	located := Feature{
+		ID:   "https://api.weather.gov/stations/KSFO",
+		Type: "Feature",
+		Geo:  Geometry{Type: "Point", Coordinates: []float64{-122.36558, 37.61961}},
+	}
+	located.Props.StationID = "KSFO"
+
+	// a cached feature without its type or a location
+	unlocated := Feature{ID: "https://api.weather.gov/stations/KNOP"}
+
+	collection := NewFeatureCollection([]Feature{located, unlocated})
+	collection.Next = "cursor"
+
+	b, err := json.Marshal(collection)
+
+	if err != nil {
+		t.Fatalf("Failed marshalling the collection. %+v\n", err)
+	}
+
+	var geoJSON struct {
+		Type     string `json:"type"`
+		Next     string `json:"next"`
+		Features []struct {
+			ID         string                 `json:"id"`
+			Type       string                 `json:"type"`
+			Geometry   *Geometry              `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+
+	if err := json.Unmarshal(b, &geoJSON); err != nil {
+		t.Fatalf("Failed unmarshalling the GeoJSON. %+v\n", err)
+	}
+
+	if geoJSON.Type != "FeatureCollection" || geoJSON.Next != "cursor" || len(geoJSON.Features) != 2 {
+		t.Fatalf("Collection not as expected. %s\n", b)
+	}
+
+	first, second := geoJSON.Features[0], geoJSON.Features[1]
+
+	if first.Type != "Feature" || first.Geometry == nil || first.Geometry.Coordinates[0] != -122.36558 || first.Properties["stationIdentifier"] != "KSFO" {
+		t.Errorf("Located feature not as expected. %+v\n", first)
+	}
+
+	if second.Type != "Feature" || second.Geometry != nil || second.Properties == nil {
+		t.Errorf("Expected a Feature with a null geometry. %+v\n", second)
+	}
+
+	if b, _ := json.Marshal(NewFeatureCollection(nil)); string(b) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("Empty collection not as expected. %s\n", b)
+	}
+}
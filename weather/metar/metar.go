@@ -0,0 +1,550 @@
+// Package metar decodes METAR and SPECI reports, such as the raw message of a weather service observation
+package metar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTooShort the report has no station or time
+var ErrTooShort = errors.New("METAR report too short")
+
+// Wind the reported wind. The speeds are in the report's unit
+type Wind struct {
+	Direction *int   `json:"direction,omitempty"` // degrees true, nil when variable
+	Variable  bool   `json:"variable,omitempty"`
+	Speed     int    `json:"speed"`
+	Gust      *int   `json:"gust,omitempty"`
+	Unit      string `json:"unit"`           // KT, MPS or KMH
+	From      *int   `json:"from,omitempty"` // the direction varies between From and To
+	To        *int   `json:"to,omitempty"`
+}
+
+// Visibility the prevailing visibility
+type Visibility struct {
+	Distance float64 `json:"distance"`
+	Unit     string  `json:"unit"` // SM or m
+	LessThan bool    `json:"lessThan,omitempty"`
+	MoreThan bool    `json:"moreThan,omitempty"`
+}
+
+// Meters the visibility in meters
+func (v Visibility) Meters() float64 {
+	if v.Unit == "SM" {
+		return v.Distance * 1609.344
+	}
+
+	return v.Distance
+}
+
+// RunwayVisualRange the visual range along a runway
+type RunwayVisualRange struct {
+	Runway   string `json:"runway"`
+	Range    int    `json:"range"`
+	MaxRange *int   `json:"maxRange,omitempty"` // the range varies up to MaxRange
+	Unit     string `json:"unit"`               // FT or m
+	LessThan bool   `json:"lessThan,omitempty"`
+	MoreThan bool   `json:"moreThan,omitempty"`
+	Trend    string `json:"trend,omitempty"` // U up, D down or N no change
+}
+
+// Weather a present weather group such as -SHRA
+type Weather struct {
+	Code       string   `json:"code"`
+	Intensity  string   `json:"intensity,omitempty"` // -, + or VC
+	Descriptor string   `json:"descriptor,omitempty"`
+	Phenomena  []string `json:"phenomena,omitempty"`
+}
+
+// Cloud a cloud layer. Base is nil when it was not reported
+type Cloud struct {
+	Cover string `json:"cover"`          // SKC, CLR, NSC, NCD, FEW, SCT, BKN, OVC or VV for an obscured sky
+	Base  *int   `json:"base,omitempty"` // feet above the ground
+	Type  string `json:"type,omitempty"` // CB or TCU
+}
+
+// Pressure the altimeter setting
+type Pressure struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"` // inHg or hPa
+}
+
+// Hectopascals the pressure in hectopascals
+func (p Pressure) Hectopascals() float64 {
+	if p.Unit == "inHg" {
+		return p.Value * 33.86389
+	}
+
+	return p.Value
+}
+
+// Remarks the remarks section, with the groups that were decoded. Temperatures are degrees Celsius
+// and precipitation is inches
+type Remarks struct {
+	Text                  string   `json:"text"`
+	Station               string   `json:"station,omitempty"`          // AO1 or AO2
+	SeaLevelPressure      *float64 `json:"seaLevelPressure,omitempty"` // hPa
+	Temperature           *float64 `json:"temperature,omitempty"`
+	Dewpoint              *float64 `json:"dewpoint,omitempty"`
+	PrecipitationLastHour *float64 `json:"precipitationLastHour,omitempty"`
+	Precipitation6Hours   *float64 `json:"precipitation6Hours,omitempty"`
+	MaxTemperature6Hours  *float64 `json:"maxTemperature6Hours,omitempty"`
+	MinTemperature6Hours  *float64 `json:"minTemperature6Hours,omitempty"`
+	MaxTemperature24Hours *float64 `json:"maxTemperature24Hours,omitempty"`
+	MinTemperature24Hours *float64 `json:"minTemperature24Hours,omitempty"`
+}
+
+// Conditions the wind, visibility, present weather and clouds, reported by a METAR and forecast by a TAF
+type Conditions struct {
+	Wind       *Wind       `json:"wind,omitempty"`
+	CAVOK      bool        `json:"cavok,omitempty"`
+	Visibility *Visibility `json:"visibility,omitempty"`
+	Weather    []Weather   `json:"weather,omitempty"`
+	Clouds     []Cloud     `json:"clouds,omitempty"`
+}
+
+// Report a decoded METAR or SPECI. Temperatures are degrees Celsius
+type Report struct {
+	Raw       string `json:"raw"`
+	Type      string `json:"type"` // METAR or SPECI
+	Station   string `json:"station"`
+	Day       int    `json:"day"`
+	Hour      int    `json:"hour"`
+	Minute    int    `json:"minute"`
+	Auto      bool   `json:"auto,omitempty"`
+	Corrected bool   `json:"corrected,omitempty"`
+	Missing   bool   `json:"missing,omitempty"` // a NIL report
+	Conditions
+	RunwayVisualRanges []RunwayVisualRange `json:"runwayVisualRanges,omitempty"`
+	Temperature        *float64            `json:"temperature,omitempty"`
+	Dewpoint           *float64            `json:"dewpoint,omitempty"`
+	Altimeter          *Pressure           `json:"altimeter,omitempty"`
+	Trend              string              `json:"trend,omitempty"`
+	Maintenance        bool                `json:"maintenance,omitempty"` // the station needs maintenance
+	Remarks            Remarks             `json:"remarks"`
+	Unparsed           []string            `json:"unparsed,omitempty"`
+}
+
+// Time the report time in the month of ref, or the month before when that would be after ref
+func (r Report) Time(ref time.Time) time.Time {
+
+	ref = ref.UTC()
+
+	t := time.Date(ref.Year(), ref.Month(), r.Day, r.Hour, r.Minute, 0, 0, time.UTC)
+
+	// a report from late in the previous month
+	if t.After(ref.Add(24 * time.Hour)) {
+		t = time.Date(ref.Year(), ref.Month()-1, r.Day, r.Hour, r.Minute, 0, 0, time.UTC)
+	}
+
+	return t
+}
+
+var (
+	stationGroup    = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	timeGroup       = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	windGroup       = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	windVariation   = regexp.MustCompile(`^(\d{3})V(\d{3})$`)
+	wholeMiles      = regexp.MustCompile(`^\d$`)
+	milesGroup      = regexp.MustCompile(`^([MP])?(\d+|\d+/\d+)SM$`)
+	metersGroup     = regexp.MustCompile(`^(\d{4})(NDV)?$`)
+	rvrGroup        = regexp.MustCompile(`^R(\d{2}[LCR]?)/([PM])?(\d{4})(?:V([PM])?(\d{4}))?(FT)?/?([UDN])?$`)
+	weatherGroup    = regexp.MustCompile(`^(-|\+|VC)?(MI|PR|BC|DR|BL|SH|TS|FZ)?((?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*)$`)
+	cloudGroup      = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3}|///)(CB|TCU|///)?$`)
+	clearGroup      = regexp.MustCompile(`^(SKC|CLR|NSC|NCD)$`)
+	temperatureGrp  = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?$`)
+	altimeterGroup  = regexp.MustCompile(`^([AQ])(\d{4})$`)
+	trendGroup      = regexp.MustCompile(`^(NOSIG|BECMG|TEMPO)$`)
+	slpRemark       = regexp.MustCompile(`^SLP(\d{3})$`)
+	tempRemark      = regexp.MustCompile(`^T([01])(\d{3})(?:([01])(\d{3}))?$`)
+	hourPrecip      = regexp.MustCompile(`^P(\d{4})$`)
+	sixHourPrecip   = regexp.MustCompile(`^6(\d{4})$`)
+	sixHourTemp     = regexp.MustCompile(`^([12])([01])(\d{3})$`)
+	dayTempRemark   = regexp.MustCompile(`^4([01])(\d{3})([01])(\d{3})$`)
+	automatedRemark = regexp.MustCompile(`^AO[12]A?$`)
+)
+
+// Parse decode the METAR or SPECI. Groups that are not understood are kept in Unparsed
+func Parse(raw string) (Report, error) {
+
+	report := Report{Raw: raw, Type: "METAR"}
+
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(raw), "="))
+
+	if len(fields) > 0 && (fields[0] == "METAR" || fields[0] == "SPECI") {
+		report.Type = fields[0]
+		fields = fields[1:]
+	}
+
+	if len(fields) < 2 {
+		return report, ErrTooShort
+	}
+
+	if !stationGroup.MatchString(fields[0]) {
+		return report, fmt.Errorf("Invalid station %q", fields[0])
+	}
+
+	report.Station = fields[0]
+
+	match := timeGroup.FindStringSubmatch(fields[1])
+
+	if match == nil {
+		return report, fmt.Errorf("Invalid time %q", fields[1])
+	}
+
+	report.Day, _ = strconv.Atoi(match[1])
+	report.Hour, _ = strconv.Atoi(match[2])
+	report.Minute, _ = strconv.Atoi(match[3])
+
+	body := fields[2:]
+
+	for i, field := range body {
+		if field == "RMK" {
+			report.Maintenance = body[len(body)-1] == "$"
+			report.Remarks = parseRemarks(body[i+1:])
+			body = body[:i]
+			break
+		}
+	}
+
+	for i, field := range body {
+		if trendGroup.MatchString(field) {
+			report.Trend = strings.Join(body[i:], " ")
+			body = body[:i]
+			break
+		}
+	}
+
+	report.Unparsed = report.Decode(body, report.parseGroup)
+
+	return report, nil
+}
+
+// parseGroup decode a group only a METAR has, false when it is not understood
+func (r *Report) parseGroup(field string) bool {
+
+	switch field {
+	case "AUTO":
+		r.Auto = true
+		return true
+	case "COR", "CCA":
+		r.Corrected = true
+		return true
+	case "NIL":
+		r.Missing = true
+		return true
+	case "$":
+		r.Maintenance = true
+		return true
+	}
+
+	if match := rvrGroup.FindStringSubmatch(field); match != nil {
+		rvr := RunwayVisualRange{
+			Runway:   match[1],
+			Range:    *atoi(match[3]),
+			Unit:     "m",
+			LessThan: match[2] == "M",
+			MoreThan: match[2] == "P",
+			Trend:    match[7],
+		}
+
+		if match[5] != "" {
+			rvr.MaxRange = atoi(match[5])
+			rvr.MoreThan = match[4] == "P"
+		}
+
+		if match[6] == "FT" {
+			rvr.Unit = "FT"
+		}
+
+		r.RunwayVisualRanges = append(r.RunwayVisualRanges, rvr)
+		return true
+	}
+
+	if match := temperatureGrp.FindStringSubmatch(field); match != nil {
+		r.Temperature = celsius(match[1])
+		r.Dewpoint = celsius(match[2])
+		return true
+	}
+
+	if match := altimeterGroup.FindStringSubmatch(field); match != nil {
+		value := float64(*atoi(match[2]))
+
+		if match[1] == "A" {
+			r.Altimeter = &Pressure{Value: value / 100, Unit: "inHg"}
+		} else {
+			r.Altimeter = &Pressure{Value: value, Unit: "hPa"}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// Decode the wind, visibility, weather and cloud groups of the fields. Each field is first offered to other,
+// when given, for the groups of the caller's report. The fields neither understands are returned
+func (c *Conditions) Decode(fields []string, other func(field string) bool) []string {
+
+	var unparsed []string
+
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+
+		// 1 1/2SM is written as two groups
+		if wholeMiles.MatchString(field) && i+1 < len(fields) && milesGroup.MatchString(fields[i+1]) && c.Visibility == nil {
+			c.parseMiles(field, fields[i+1])
+			i++
+			continue
+		}
+
+		if other != nil && other(field) {
+			continue
+		}
+
+		if !c.parseGroup(field) {
+			unparsed = append(unparsed, field)
+		}
+	}
+
+	return unparsed
+}
+
+// parseGroup decode a wind, visibility, weather or cloud group, false when it is not understood
+func (c *Conditions) parseGroup(field string) bool {
+
+	if field == "CAVOK" {
+		c.CAVOK = true
+		c.Visibility = &Visibility{Distance: 10000, Unit: "m", MoreThan: true}
+		return true
+	}
+
+	if match := windGroup.FindStringSubmatch(field); match != nil && c.Wind == nil {
+		wind := Wind{Unit: match[4]}
+
+		if match[1] == "VRB" {
+			wind.Variable = true
+		} else {
+			wind.Direction = atoi(match[1])
+		}
+
+		wind.Speed = *atoi(match[2])
+
+		if match[3] != "" {
+			wind.Gust = atoi(match[3])
+		}
+
+		c.Wind = &wind
+		return true
+	}
+
+	if match := windVariation.FindStringSubmatch(field); match != nil && c.Wind != nil {
+		c.Wind.From = atoi(match[1])
+		c.Wind.To = atoi(match[2])
+		return true
+	}
+
+	if milesGroup.MatchString(field) && c.Visibility == nil {
+		return c.parseMiles("", field)
+	}
+
+	if match := metersGroup.FindStringSubmatch(field); match != nil && c.Visibility == nil {
+		meters := float64(*atoi(match[1]))
+		c.Visibility = &Visibility{Distance: meters, Unit: "m", MoreThan: meters == 9999}
+		return true
+	}
+
+	if match := cloudGroup.FindStringSubmatch(field); match != nil {
+		cloud := Cloud{Cover: match[1]}
+
+		if height := atoi(match[2]); height != nil {
+			base := *height * 100
+			cloud.Base = &base
+		}
+
+		if match[3] != "///" {
+			cloud.Type = match[3]
+		}
+
+		c.Clouds = append(c.Clouds, cloud)
+		return true
+	}
+
+	if clearGroup.MatchString(field) {
+		c.Clouds = append(c.Clouds, Cloud{Cover: field})
+		return true
+	}
+
+	if match := weatherGroup.FindStringSubmatch(field); match != nil && (match[2] != "" || match[3] != "") {
+		weather := Weather{Code: field, Intensity: match[1], Descriptor: match[2]}
+
+		for p := match[3]; p != ""; p = p[2:] {
+			weather.Phenomena = append(weather.Phenomena, p[:2])
+		}
+
+		c.Weather = append(c.Weather, weather)
+		return true
+	}
+
+	return false
+}
+
+// parseMiles decode statute miles, with the whole miles of a mixed fraction when given
+func (c *Conditions) parseMiles(whole string, field string) bool {
+
+	match := milesGroup.FindStringSubmatch(field)
+
+	distance, err := fraction(match[2])
+
+	if err != nil {
+		return false
+	}
+
+	if whole != "" {
+		distance += float64(*atoi(whole))
+	}
+
+	c.Visibility = &Visibility{
+		Distance: distance,
+		Unit:     "SM",
+		LessThan: match[1] == "M",
+		MoreThan: match[1] == "P",
+	}
+
+	return true
+}
+
+// parseRemarks decode the automated remark groups
+func parseRemarks(fields []string) Remarks {
+
+	remarks := Remarks{Text: strings.Join(fields, " ")}
+
+	for _, field := range fields {
+
+		if automatedRemark.MatchString(field) {
+			remarks.Station = field
+			continue
+		}
+
+		if match := slpRemark.FindStringSubmatch(field); match != nil {
+			tenths := float64(*atoi(match[1])) / 10
+
+			// the leading 9 or 10 is left off
+			pressure := 1000 + tenths
+
+			if tenths >= 50 {
+				pressure = 900 + tenths
+			}
+
+			remarks.SeaLevelPressure = &pressure
+			continue
+		}
+
+		if match := tempRemark.FindStringSubmatch(field); match != nil {
+			remarks.Temperature = tenthsCelsius(match[1], match[2])
+
+			if match[3] != "" {
+				remarks.Dewpoint = tenthsCelsius(match[3], match[4])
+			}
+
+			continue
+		}
+
+		if match := hourPrecip.FindStringSubmatch(field); match != nil {
+			remarks.PrecipitationLastHour = hundredths(match[1])
+			continue
+		}
+
+		if match := sixHourPrecip.FindStringSubmatch(field); match != nil {
+			remarks.Precipitation6Hours = hundredths(match[1])
+			continue
+		}
+
+		if match := sixHourTemp.FindStringSubmatch(field); match != nil {
+			if match[1] == "1" {
+				remarks.MaxTemperature6Hours = tenthsCelsius(match[2], match[3])
+			} else {
+				remarks.MinTemperature6Hours = tenthsCelsius(match[2], match[3])
+			}
+
+			continue
+		}
+
+		if match := dayTempRemark.FindStringSubmatch(field); match != nil {
+			remarks.MaxTemperature24Hours = tenthsCelsius(match[1], match[2])
+			remarks.MinTemperature24Hours = tenthsCelsius(match[3], match[4])
+		}
+	}
+
+	return remarks
+}
+
+// atoi the number, nil when it is not one such as ///
+func atoi(s string) *int {
+	i, err := strconv.Atoi(s)
+
+	if err != nil {
+		return nil
+	}
+
+	return &i
+}
+
+// celsius a temperature such as M05, nil when it is missing
+func celsius(s string) *float64 {
+	i := atoi(strings.TrimPrefix(s, "M"))
+
+	if i == nil {
+		return nil
+	}
+
+	t := float64(*i)
+
+	if strings.HasPrefix(s, "M") {
+		t = -t
+	}
+
+	return &t
+}
+
+// tenthsCelsius a remark temperature, a 1 sign is below zero
+func tenthsCelsius(sign string, tenths string) *float64 {
+	t := float64(*atoi(tenths)) / 10
+
+	if sign == "1" {
+		t = -t
+	}
+
+	return &t
+}
+
+// hundredths inches in hundredths, such as 0015
+func hundredths(s string) *float64 {
+	inches := float64(*atoi(s)) / 100
+
+	return &inches
+}
+
+// fraction a whole number or a fraction such as 3/4
+func fraction(s string) (float64, error) {
+
+	parts := strings.Split(s, "/")
+
+	numerator, err := strconv.Atoi(parts[0])
+
+	if err != nil || len(parts) == 1 {
+		return float64(numerator), err
+	}
+
+	denominator, err := strconv.Atoi(parts[1])
+
+	if err != nil || denominator == 0 {
+		return 0, fmt.Errorf("Invalid fraction %q", s)
+	}
+
+	return float64(numerator) / float64(denominator), nil
+}
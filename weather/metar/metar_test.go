@@ -0,0 +1,184 @@
+package metar
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCorpus(t *testing.T) {
+
+	file, err := os.Open("testdata/corpus.txt")
+
+	if err != nil {
+		t.Fatalf("Opening the corpus failed. %+v\n", err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	reports := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		report, err := Parse(line)
+
+		if err != nil {
+			t.Errorf("Parsing %q failed. %+v\n", line, err)
+			continue
+		}
+
+		if len(report.Unparsed) > 0 {
+			t.Errorf("Groups of %q not decoded. %v\n", line, report.Unparsed)
+		}
+
+		if report.Wind == nil || report.Visibility == nil || report.Temperature == nil {
+			t.Errorf("Wind, visibility or temperature of %q missing. %+v\n", line, report)
+		}
+
+		reports++
+	}
+
+	if reports < 20 {
+		t.Errorf("Expected the corpus to have at least 20 reports. %d\n", reports)
+	}
+}
+
+func TestParse(t *testing.T) {
+
+	report, err := Parse("SPECI KDEN 181112Z 02008G18KT 1 1/2SM R35L/2600V4000FT -SN FZFG VV004 M02/M03 A3012 RMK AO2 SLP215 P0001 T10171028 $")
+
+	if err != nil {
+		t.Fatalf("Parsing failed. %+v\n", err)
+	}
+
+	if report.Type != "SPECI" || report.Station != "KDEN" || report.Day != 18 || report.Hour != 11 || report.Minute != 12 {
+		t.Errorf("Header not as expected. %+v\n", report)
+	}
+
+	if w := report.Wind; w == nil || *w.Direction != 20 || w.Speed != 8 || *w.Gust != 18 || w.Unit != "KT" {
+		t.Errorf("Wind not as expected. %+v\n", w)
+	}
+
+	if v := report.Visibility; v == nil || v.Distance != 1.5 || v.Unit != "SM" {
+		t.Errorf("Visibility not as expected. %+v\n", v)
+	}
+
+	if len(report.RunwayVisualRanges) != 1 {
+		t.Fatalf("Expected one runway visual range. %+v\n", report.RunwayVisualRanges)
+	}
+
+	if rvr := report.RunwayVisualRanges[0]; rvr.Runway != "35L" || rvr.Range != 2600 || *rvr.MaxRange != 4000 || rvr.Unit != "FT" {
+		t.Errorf("Runway visual range not as expected. %+v\n", rvr)
+	}
+
+	if len(report.Weather) != 2 || report.Weather[0].Intensity != "-" || report.Weather[0].Phenomena[0] != "SN" ||
+		report.Weather[1].Descriptor != "FZ" || report.Weather[1].Phenomena[0] != "FG" {
+		t.Errorf("Present weather not as expected. %+v\n", report.Weather)
+	}
+
+	if len(report.Clouds) != 1 || report.Clouds[0].Cover != "VV" || *report.Clouds[0].Base != 400 {
+		t.Errorf("Vertical visibility not as expected. %+v\n", report.Clouds)
+	}
+
+	if *report.Temperature != -2 || *report.Dewpoint != -3 {
+		t.Errorf("Temperature and dewpoint not as expected. %v %v\n", *report.Temperature, *report.Dewpoint)
+	}
+
+	if report.Altimeter == nil || report.Altimeter.Value != 30.12 || math.Abs(report.Altimeter.Hectopascals()-1019.98) > 0.01 {
+		t.Errorf("Altimeter not as expected. %+v\n", report.Altimeter)
+	}
+
+	remarks := report.Remarks
+
+	if remarks.Station != "AO2" || *remarks.SeaLevelPressure != 1021.5 || *remarks.PrecipitationLastHour != 0.01 ||
+		*remarks.Temperature != -1.7 || *remarks.Dewpoint != -2.8 {
+		t.Errorf("Remarks not as expected. %+v\n", remarks)
+	}
+
+	if !report.Maintenance {
+		t.Errorf("Expected the maintenance indicator\n")
+	}
+}
+
+func TestParseInternational(t *testing.T) {
+
+	report, err := Parse("EDDF 181050Z VRB02KT 0800 R25R/P1500U FG BKN002 08/08 Q1021 BECMG 2000")
+
+	if err != nil {
+		t.Fatalf("Parsing failed. %+v\n", err)
+	}
+
+	if !report.Wind.Variable || report.Wind.Direction != nil {
+		t.Errorf("Expected a variable wind. %+v\n", report.Wind)
+	}
+
+	if report.Visibility.Meters() != 800 {
+		t.Errorf("Visibility not as expected. %+v\n", report.Visibility)
+	}
+
+	if rvr := report.RunwayVisualRanges[0]; !rvr.MoreThan || rvr.Range != 1500 || rvr.Unit != "m" || rvr.Trend != "U" {
+		t.Errorf("Runway visual range not as expected. %+v\n", rvr)
+	}
+
+	if report.Altimeter.Unit != "hPa" || report.Altimeter.Hectopascals() != 1021 {
+		t.Errorf("QNH not as expected. %+v\n", report.Altimeter)
+	}
+
+	if report.Trend != "BECMG 2000" {
+		t.Errorf("Trend not as expected. %s\n", report.Trend)
+	}
+}
+
+func TestParseRemarks(t *testing.T) {
+
+	remarks := parseRemarks(strings.Fields("AO2 SLP982 T02391044 10267 20161 401781056 60015"))
+
+	if *remarks.SeaLevelPressure != 998.2 {
+		t.Errorf("Sea level pressure not as expected. %v\n", *remarks.SeaLevelPressure)
+	}
+
+	if *remarks.MaxTemperature6Hours != 26.7 || *remarks.MinTemperature6Hours != 16.1 {
+		t.Errorf("Six hour temperatures not as expected. %+v\n", remarks)
+	}
+
+	if *remarks.MaxTemperature24Hours != 17.8 || *remarks.MinTemperature24Hours != -5.6 {
+		t.Errorf("24 hour temperatures not as expected. %v %v\n", *remarks.MaxTemperature24Hours, *remarks.MinTemperature24Hours)
+	}
+
+	if *remarks.Precipitation6Hours != 0.15 {
+		t.Errorf("Six hour precipitation not as expected. %v\n", *remarks.Precipitation6Hours)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+
+	for _, raw := range []string{"", "KSFO", "METAR ksfo 181056Z", "KSFO 1810Z 29012KT"} {
+		if _, err := Parse(raw); err == nil {
+			t.Errorf("Expected %q not to parse\n", raw)
+		}
+	}
+}
+
+func TestReportTime(t *testing.T) {
+
+	report := Report{Day: 31, Hour: 23, Minute: 56}
+
+	if at := report.Time(time.Date(2026, 11, 1, 0, 5, 0, 0, time.UTC)); !at.Equal(time.Date(2026, 10, 31, 23, 56, 0, 0, time.UTC)) {
+		t.Errorf("Expected the report from the previous month. %s\n", at)
+	}
+
+	report = Report{Day: 18, Hour: 10, Minute: 56}
+
+	if at := report.Time(time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC)); !at.Equal(time.Date(2026, 10, 18, 10, 56, 0, 0, time.UTC)) {
+		t.Errorf("Report time not as expected. %s\n", at)
+	}
+}
@@ -0,0 +1,183 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CloudLayer a reported cloud layer
+type CloudLayer struct {
+	Base   Quantity `json:"base"`
+	Amount string   `json:"amount"`
+}
+
+// ObservationProperties the observed weather
+type ObservationProperties struct {
+	ID                        string       `json:"@id"`
+	Type                      string       `json:"@type"`
+	Elevation                 Quantity     `json:"elevation"`
+	Station                   string       `json:"station"`
+	Timestamp                 time.Time    `json:"timestamp"`
+	RawMessage                string       `json:"rawMessage"`
+	TextDescription           string       `json:"textDescription"`
+	Icon                      string       `json:"icon"`
+	Temperature               Quantity     `json:"temperature"`
+	Dewpoint                  Quantity     `json:"dewpoint"`
+	WindDirection             Quantity     `json:"windDirection"`
+	WindSpeed                 Quantity     `json:"windSpeed"`
+	WindGust                  Quantity     `json:"windGust"`
+	BarometricPressure        Quantity     `json:"barometricPressure"`
+	SeaLevelPressure          Quantity     `json:"seaLevelPressure"`
+	Visibility                Quantity     `json:"visibility"`
+	MaxTemperatureLast24Hours Quantity     `json:"maxTemperatureLast24Hours"`
+	MinTemperatureLast24Hours Quantity     `json:"minTemperatureLast24Hours"`
+	PrecipitationLastHour     Quantity     `json:"precipitationLastHour"`
+	RelativeHumidity          Quantity     `json:"relativeHumidity"`
+	WindChill                 Quantity     `json:"windChill"`
+	HeatIndex                 Quantity     `json:"heatIndex"`
+	CloudLayers               []CloudLayer `json:"cloudLayers"`
+}
+
+// Observation a station observation
+type Observation struct {
+	ID    string                `json:"id"`
+	Type  string                `json:"type"`
+	Geo   Geometry              `json:"geometry"`
+	Props ObservationProperties `json:"properties"`
+}
+
+// StationID the station identifier at the end of the station URL, or in the observation ID
+func (o Observation) StationID() string {
+	if o.Props.Station != "" {
+		return o.Props.Station[strings.LastIndex(o.Props.Station, "/")+1:]
+	}
+
+	parts := strings.Split(o.ID, "/")
+
+	for i, part := range parts {
+		if part == "stations" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+
+	return ""
+}
+
+// ObservationCollection the observations for a station
+type ObservationCollection struct {
+	Type         string        `json:"type"`
+	Observations []Observation `json:"features"`
+}
+
+// ObservationQuery limits the observations returned. Zero values are not sent
+type ObservationQuery struct {
+	Start time.Time
+	End   time.Time
+	Limit int
+}
+
+// GetLatestObservation the latest observation for the station ID
+func GetLatestObservation(stationID string) (Observation, error) {
+	return DefaultClient.GetLatestObservation(stationID)
+}
+
+// GetLatestObservationContext the latest observation for the station ID
+func GetLatestObservationContext(ctx context.Context, stationID string) (Observation, error) {
+	return DefaultClient.GetLatestObservationContext(ctx, stationID)
+}
+
+// GetObservations the observations for the station ID
+func GetObservations(stationID string, query ObservationQuery) ([]Observation, error) {
+	return DefaultClient.GetObservations(stationID, query)
+}
+
+// GetObservationsContext the observations for the station ID
+func GetObservationsContext(ctx context.Context, stationID string, query ObservationQuery) ([]Observation, error) {
+	return DefaultClient.GetObservationsContext(ctx, stationID, query)
+}
+
+// GetLatestObservation wraps GetLatestObservationContext using context.Background
+func (c *Client) GetLatestObservation(stationID string) (Observation, error) {
+	return c.GetLatestObservationContext(context.Background(), stationID)
+}
+
+// GetLatestObservationContext the latest observation for the station ID
+func (c *Client) GetLatestObservationContext(ctx context.Context, stationID string) (Observation, error) {
+
+	resp, err := c.rest.R().
+		SetContext(ctx).
+		SetPathParams(map[string]string{"stationId": stationID}).
+		Get("/stations/{stationId}/observations/latest")
+
+	if err != nil {
+		return Observation{}, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return Observation{}, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	var observation Observation
+
+	err = json.Unmarshal(resp.Body(), &observation)
+
+	if err != nil {
+		log.Printf("Failed unmarshalling into observation %s", err)
+		return Observation{}, err
+	}
+
+	return observation, nil
+}
+
+// GetObservations wraps GetObservationsContext using context.Background
+func (c *Client) GetObservations(stationID string, query ObservationQuery) ([]Observation, error) {
+	return c.GetObservationsContext(context.Background(), stationID, query)
+}
+
+// GetObservationsContext the observations for the station ID
+func (c *Client) GetObservationsContext(ctx context.Context, stationID string, query ObservationQuery) ([]Observation, error) {
+
+	params := make(map[string]string)
+
+	if !query.Start.IsZero() {
+		params["start"] = query.Start.UTC().Format(time.RFC3339)
+	}
+
+	if !query.End.IsZero() {
+		params["end"] = query.End.UTC().Format(time.RFC3339)
+	}
+
+	if query.Limit > 0 {
+		params["limit"] = strconv.Itoa(query.Limit)
+	}
+
+	resp, err := c.rest.R().
+		SetContext(ctx).
+		SetPathParams(map[string]string{"stationId": stationID}).
+		SetQueryParams(params).
+		Get("/stations/{stationId}/observations")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	var observations ObservationCollection
+
+	err = json.Unmarshal(resp.Body(), &observations)
+
+	if err != nil {
+		log.Printf("Failed unmarshalling into observations %s", err)
+		return nil, err
+	}
+
+	return observations.Observations, nil
+}
@@ -0,0 +1,118 @@
+package weather
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const latestObservation = `{
+	"id": "https://api.weather.gov/stations/KSFO/observations/2026-10-18T10:56:00+00:00",
+	"type": "Feature",
+	"geometry": {
+		"type": "Point",
+		"coordinates": [-122.37, 37.62]
+	},
+	"properties": {
+		"@id": "https://api.weather.gov/stations/KSFO/observations/2026-10-18T10:56:00+00:00",
+		"@type": "wx:ObservationStation",
+		"elevation": {"unitCode": "wmoUnit:m", "value": 3},
+		"station": "https://api.weather.gov/stations/KSFO",
+		"timestamp": "2026-10-18T10:56:00+00:00",
+		"rawMessage": "KSFO 181056Z 29012KT 10SM FEW008 14/11 A2995",
+		"textDescription": "Mostly Clear",
+		"temperature": {"unitCode": "wmoUnit:degC", "value": 14, "qualityControl": "V"},
+		"dewpoint": {"unitCode": "wmoUnit:degC", "value": 11, "qualityControl": "V"},
+		"windDirection": {"unitCode": "wmoUnit:degree_(angle)", "value": 290, "qualityControl": "V"},
+		"windSpeed": {"unitCode": "wmoUnit:km_h-1", "value": 22.224, "qualityControl": "V"},
+		"windGust": {"unitCode": "wmoUnit:km_h-1", "value": null, "qualityControl": "Z"},
+		"barometricPressure": {"unitCode": "wmoUnit:Pa", "value": 101420, "qualityControl": "V"},
+		"visibility": {"unitCode": "wmoUnit:m", "value": 16090, "qualityControl": "C"},
+		"cloudLayers": [{"base": {"unitCode": "wmoUnit:m", "value": 240}, "amount": "FEW"}]
+	}
+}`
+
+func TestGetLatestObservation(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stations/KSFO/observations/latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprint(w, latestObservation)
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	observation, err := client.GetLatestObservation("KSFO")
+
+	if err != nil {
+		t.Fatalf("Getting latest observation failed: %+v\n", err)
+	}
+
+	if observation.Props.Temperature.Value == nil || *observation.Props.Temperature.Value != 14 {
+		t.Errorf("Temperature not as expected. %+v\n", observation.Props.Temperature)
+	}
+
+	if observation.Props.WindGust.Value != nil {
+		t.Errorf("Wind gust should be null. %v\n", *observation.Props.WindGust.Value)
+	}
+
+	if observation.Props.TextDescription != "Mostly Clear" {
+		t.Errorf("Text description not as expected. %s\n", observation.Props.TextDescription)
+	}
+
+	if !observation.Props.Timestamp.Equal(time.Date(2026, 10, 18, 10, 56, 0, 0, time.UTC)) {
+		t.Errorf("Timestamp not as expected. %s\n", observation.Props.Timestamp)
+	}
+
+	if observation.StationID() != "KSFO" {
+		t.Errorf("Station ID not as expected. %s\n", observation.StationID())
+	}
+
+	observation.Props.Station = ""
+
+	if observation.StationID() != "KSFO" {
+		t.Errorf("Station ID from the observation ID not as expected. %s\n", observation.StationID())
+	}
+}
+
+func TestGetObservationsQuery(t *testing.T) {
+
+	var start, end, limit string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start = r.URL.Query().Get("start")
+		end = r.URL.Query().Get("end")
+		limit = r.URL.Query().Get("limit")
+
+		fmt.Fprintf(w, `{"type": "FeatureCollection", "features": [%s]}`, latestObservation)
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	observations, err := client.GetObservations("KSFO", ObservationQuery{
+		Start: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Limit: 10,
+	})
+
+	if err != nil {
+		t.Fatalf("Getting observations failed: %+v\n", err)
+	}
+
+	if len(observations) != 1 {
+		t.Errorf("Expected 1 observation. %d\n", len(observations))
+	}
+
+	if start != "2026-10-17T00:00:00Z" || end != "2026-10-18T00:00:00Z" || limit != "10" {
+		t.Errorf("Query parameters not as expected. start=%s end=%s limit=%s\n", start, end, limit)
+	}
+}
@@ -0,0 +1,173 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather/taf"
+)
+
+// Product a text product issued by a weather service office
+type Product struct {
+	ID              string    `json:"id"`
+	WMOCollectiveID string    `json:"wmoCollectiveId"`
+	IssuingOffice   string    `json:"issuingOffice"`
+	IssuanceTime    time.Time `json:"issuanceTime"`
+	ProductCode     string    `json:"productCode"`
+	ProductName     string    `json:"productName"`
+	ProductText     string    `json:"productText"`
+}
+
+// ProductList the products of a type, newest first. The text is not included
+type ProductList struct {
+	Products []Product `json:"@graph"`
+}
+
+// tafLocation the product location of a station, its ICAO identifier without the country prefix, KSFO is SFO
+func tafLocation(stationID string) string {
+	if len(stationID) == 4 {
+		return stationID[1:]
+	}
+
+	return stationID
+}
+
+// GetLatestProduct the newest product of the type for the location
+func GetLatestProduct(productType string, location string) (Product, error) {
+	return DefaultClient.GetLatestProduct(productType, location)
+}
+
+// GetLatestProductContext the newest product of the type for the location
+func GetLatestProductContext(ctx context.Context, productType string, location string) (Product, error) {
+	return DefaultClient.GetLatestProductContext(ctx, productType, location)
+}
+
+// GetProduct the product with its text
+func GetProduct(productID string) (Product, error) {
+	return DefaultClient.GetProduct(productID)
+}
+
+// GetProductContext the product with its text
+func GetProductContext(ctx context.Context, productID string) (Product, error) {
+	return DefaultClient.GetProductContext(ctx, productID)
+}
+
+// GetTAF the latest terminal aerodrome forecast for the station ID
+func GetTAF(stationID string) (taf.TAF, error) {
+	return DefaultClient.GetTAF(stationID)
+}
+
+// GetTAFContext the latest terminal aerodrome forecast for the station ID
+func GetTAFContext(ctx context.Context, stationID string) (taf.TAF, error) {
+	return DefaultClient.GetTAFContext(ctx, stationID)
+}
+
+// GetLatestProduct wraps GetLatestProductContext using context.Background
+func (c *Client) GetLatestProduct(productType string, location string) (Product, error) {
+	return c.GetLatestProductContext(context.Background(), productType, location)
+}
+
+// GetLatestProductContext the newest product of the type for the location, with its text
+func (c *Client) GetLatestProductContext(ctx context.Context, productType string, location string) (Product, error) {
+
+	resp, err := c.rest.R().
+		SetContext(ctx).
+		SetPathParams(map[string]string{"type": productType, "location": location}).
+		Get("/products/types/{type}/locations/{location}")
+
+	if err != nil {
+		return Product{}, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return Product{}, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	var products ProductList
+
+	err = json.Unmarshal(resp.Body(), &products)
+
+	if err != nil {
+		log.Printf("Failed unmarshalling into products %s", err)
+		return Product{}, err
+	}
+
+	if len(products.Products) == 0 {
+		return Product{}, fmt.Errorf("No %s products for %s", productType, location)
+	}
+
+	return c.GetProductContext(ctx, products.Products[0].ID)
+}
+
+// GetProduct wraps GetProductContext using context.Background
+func (c *Client) GetProduct(productID string) (Product, error) {
+	return c.GetProductContext(context.Background(), productID)
+}
+
+// GetProductContext the product with its text
+func (c *Client) GetProductContext(ctx context.Context, productID string) (Product, error) {
+
+	resp, err := c.rest.R().
+		SetContext(ctx).
+		SetPathParams(map[string]string{"productId": productID}).
+		Get("/products/{productId}")
+
+	if err != nil {
+		return Product{}, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return Product{}, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	var product Product
+
+	err = json.Unmarshal(resp.Body(), &product)
+
+	if err != nil {
+		log.Printf("Failed unmarshalling into product %s", err)
+		return Product{}, err
+	}
+
+	return product, nil
+}
+
+// GetTAF wraps GetTAFContext using context.Background
+func (c *Client) GetTAF(stationID string) (taf.TAF, error) {
+	return c.GetTAFContext(context.Background(), stationID)
+}
+
+// GetTAFContext the latest terminal aerodrome forecast for the station ID, decoded from the TAF product.
+// The station ID is not case sensitive
+func (c *Client) GetTAFContext(ctx context.Context, stationID string) (taf.TAF, error) {
+
+	stationID = strings.ToUpper(stationID)
+
+	product, err := c.GetLatestProductContext(ctx, "TAF", tafLocation(stationID))
+
+	if err != nil {
+		return taf.TAF{}, err
+	}
+
+	issued := product.IssuanceTime
+
+	if issued.IsZero() {
+		issued = time.Now()
+	}
+
+	forecast, err := taf.Parse(product.ProductText, issued)
+
+	if err != nil {
+		return taf.TAF{}, fmt.Errorf("Decoding TAF product %s failed: %s", product.ID, err)
+	}
+
+	if forecast.Station != stationID {
+		return taf.TAF{}, fmt.Errorf("TAF product %s is for %s, not %s", product.ID, forecast.Station, stationID)
+	}
+
+	return forecast, nil
+}
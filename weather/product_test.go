@@ -0,0 +1,49 @@
+package weather
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetTAF(t *testing.T) {
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/products/types/TAF/locations/SFO":
+			fmt.Fprintf(w, `{"@graph": [
+				{"id": "b3c1", "issuingOffice": "KMTR", "issuanceTime": "2026-10-18T11:20:00+00:00", "productCode": "TAF"},
+				{"id": "a0f2", "issuingOffice": "KMTR", "issuanceTime": "2026-10-18T05:20:00+00:00", "productCode": "TAF"}]}`)
+		case "/products/b3c1":
+			fmt.Fprint(w, `{"id": "b3c1", "issuingOffice": "KMTR", "issuanceTime": "2026-10-18T11:20:00+00:00", "productCode": "TAF",
+				"productText": "\n000\nFTUS46 KMTR 181120\nTAFSFO\n\nTAF\nKSFO 181120Z 1812/1918 29012KT P6SM FEW008\n     FM181800 30015G22KT P6SM SKC=\n"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	forecast, err := client.GetTAF("KSFO")
+
+	if err != nil {
+		t.Fatalf("Getting the TAF failed: %+v\n", err)
+	}
+
+	if forecast.Station != "KSFO" || len(forecast.Groups) != 2 || forecast.Groups[1].From.Hour() != 18 {
+		t.Errorf("TAF not as expected. %+v\n", forecast)
+	}
+
+	if forecast, err := client.GetTAF("ksfo"); err != nil || forecast.Station != "KSFO" {
+		t.Errorf("The station ID should not be case sensitive. %+v %+v\n", forecast, err)
+	}
+
+	if _, err := client.GetTAF("KOAK"); err == nil {
+		t.Errorf("Expected no TAF for KOAK\n")
+	}
+}
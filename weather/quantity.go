@@ -0,0 +1,439 @@
+package weather
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit a unit of measure, the unit code without its wmoUnit: or unit: prefix
+type Unit string
+
+// The units the weather service reports and the units they convert to
+const (
+	Celsius           Unit = "degC"
+	Fahrenheit        Unit = "degF"
+	Kelvin            Unit = "K"
+	Meter             Unit = "m"
+	Kilometer         Unit = "km"
+	Millimeter        Unit = "mm"
+	Foot              Unit = "ft"
+	Mile              Unit = "mi"
+	Inch              Unit = "in"
+	MetersPerSecond   Unit = "m_s-1"
+	KilometersPerHour Unit = "km_h-1"
+	MilesPerHour      Unit = "mi_h-1"
+	Knot              Unit = "kt"
+	Pascal            Unit = "Pa"
+	Hectopascal       Unit = "hPa"
+	InchesOfMercury   Unit = "inHg"
+	Percent           Unit = "percent"
+	Degree            Unit = "degree_(angle)"
+)
+
+// wmoUnits the units in the WMO code table the weather service writes as wmoUnit:. The US customary
+// units are not WMO codes and are written as unit:
+var wmoUnits = map[Unit]bool{
+	Celsius:           true,
+	Fahrenheit:        true,
+	Kelvin:            true,
+	Meter:             true,
+	Kilometer:         true,
+	Millimeter:        true,
+	MetersPerSecond:   true,
+	KilometersPerHour: true,
+	Knot:              true,
+	Pascal:            true,
+	Hectopascal:       true,
+	Percent:           true,
+	Degree:            true,
+}
+
+// unitAliases the other codes seen for a unit
+var unitAliases = map[string]Unit{
+	"Cel":          Celsius,
+	"[degF]":       Fahrenheit,
+	"m/s":          MetersPerSecond,
+	"km/h":         KilometersPerHour,
+	"mph":          MilesPerHour,
+	"[mi_i]/h":     MilesPerHour,
+	"[kn_i]":       Knot,
+	"[in_i'Hg]":    InchesOfMercury,
+	"%":            Percent,
+	"deg":          Degree,
+	"degree":       Degree,
+	"degrees_true": Degree,
+	"[ft_i]":       Foot,
+	"[mi_i]":       Mile,
+	"[in_i]":       Inch,
+}
+
+// dimension what a unit measures, units only convert within a dimension
+type dimension int
+
+const (
+	dimensionless dimension = iota
+	temperature
+	length
+	speed
+	pressure
+)
+
+// unitScale the dimension of the unit and how many of the dimension's base unit it is. The
+// base units are the meter, meters per second and the pascal. Temperatures are not scaled
+var unitScale = map[Unit]struct {
+	dimension dimension
+	scale     float64
+}{
+	Celsius:           {temperature, 0},
+	Fahrenheit:        {temperature, 0},
+	Kelvin:            {temperature, 0},
+	Meter:             {length, 1},
+	Kilometer:         {length, 1000},
+	Millimeter:        {length, 0.001},
+	Foot:              {length, 0.3048},
+	Mile:              {length, 1609.344},
+	Inch:              {length, 0.0254},
+	MetersPerSecond:   {speed, 1},
+	KilometersPerHour: {speed, 1 / 3.6},
+	MilesPerHour:      {speed, 0.44704},
+	Knot:              {speed, 1852.0 / 3600},
+	Pascal:            {pressure, 1},
+	Hectopascal:       {pressure, 100},
+	InchesOfMercury:   {pressure, 3386.389},
+	Percent:           {dimensionless, 1},
+	Degree:            {dimensionless, 1},
+}
+
+// ParseUnit the unit of a unit code such as wmoUnit:degC or unit:m
+func ParseUnit(code string) (Unit, error) {
+
+	name := code
+
+	if i := strings.Index(code, ":"); i >= 0 {
+		name = code[i+1:]
+	}
+
+	if _, ok := unitScale[Unit(name)]; ok {
+		return Unit(name), nil
+	}
+
+	if unit, ok := unitAliases[name]; ok {
+		return unit, nil
+	}
+
+	return "", fmt.Errorf("Unknown unit code %q", code)
+}
+
+// Code the unit code as the weather service writes it
+func (u Unit) Code() string {
+	if wmoUnits[u] {
+		return "wmoUnit:" + string(u)
+	}
+
+	return "unit:" + string(u)
+}
+
+// Quantity a value reported by the weather service with its unit. A nil Value means the station
+// did not report it
+type Quantity struct {
+	Value          *float64 `json:"value"`
+	MaxValue       *float64 `json:"maxValue,omitempty"`
+	MinValue       *float64 `json:"minValue,omitempty"`
+	UnitCode       string   `json:"unitCode"`
+	QualityControl string   `json:"qualityControl,omitempty"`
+}
+
+// NewQuantity a quantity with the value in the unit
+func NewQuantity(value float64, unit Unit) Quantity {
+	return Quantity{Value: &value, UnitCode: unit.Code()}
+}
+
+// Unit the quantity's unit
+func (q Quantity) Unit() (Unit, error) {
+	return ParseUnit(q.UnitCode)
+}
+
+// In the value in the unit. False when there is no value or it cannot convert to the unit
+func (q Quantity) In(unit Unit) (float64, bool) {
+	if q.Value == nil {
+		return 0, false
+	}
+
+	from, err := q.Unit()
+
+	if err != nil {
+		return 0, false
+	}
+
+	return convert(*q.Value, from, unit)
+}
+
+// Convert the quantity in the unit. A null value stays null
+func (q Quantity) Convert(unit Unit) (Quantity, error) {
+
+	from, err := q.Unit()
+
+	if err != nil {
+		return q, err
+	}
+
+	if from == unit {
+		return q, nil
+	}
+
+	if _, ok := convert(0, from, unit); !ok {
+		return q, fmt.Errorf("Unable to convert %s to %s", from, unit)
+	}
+
+	converted := q
+	converted.UnitCode = unit.Code()
+
+	for _, value := range []**float64{&converted.Value, &converted.MaxValue, &converted.MinValue} {
+		if *value != nil {
+			v, _ := convert(**value, from, unit)
+			*value = &v
+		}
+	}
+
+	return converted, nil
+}
+
+// convert the value between units of the same dimension
+func convert(value float64, from Unit, to Unit) (float64, bool) {
+
+	f, fok := unitScale[from]
+	t, tok := unitScale[to]
+
+	if !fok || !tok || f.dimension != t.dimension {
+		return 0, false
+	}
+
+	switch {
+	case from == to:
+		return value, true
+	case f.dimension == temperature:
+		return fromCelsius(toCelsius(value, from), to), true
+	case f.dimension == dimensionless:
+		return 0, false
+	}
+
+	return value * f.scale / t.scale, true
+}
+
+func toCelsius(value float64, from Unit) float64 {
+	switch from {
+	case Fahrenheit:
+		return (value - 32) * 5 / 9
+	case Kelvin:
+		return value - 273.15
+	}
+
+	return value
+}
+
+func fromCelsius(value float64, to Unit) float64 {
+	switch to {
+	case Fahrenheit:
+		return value*9/5 + 32
+	case Kelvin:
+		return value + 273.15
+	}
+
+	return value
+}
+
+// UnitSystem the units values are returned in, the weather service's us or si
+type UnitSystem string
+
+// The unit systems
+const (
+	SI UnitSystem = "si"
+	US UnitSystem = "us"
+)
+
+// ParseUnitSystem the unit system named us or si
+func ParseUnitSystem(s string) (UnitSystem, error) {
+	switch UnitSystem(strings.ToLower(s)) {
+	case SI:
+		return SI, nil
+	case US:
+		return US, nil
+	}
+
+	return "", fmt.Errorf("Unknown units %q, expected us or si", s)
+}
+
+// systemUnits the unit each unit converts to in the system. Units not listed are kept
+var systemUnits = map[UnitSystem]map[Unit]Unit{
+	US: {
+		Celsius:           Fahrenheit,
+		Kelvin:            Fahrenheit,
+		Meter:             Foot,
+		Kilometer:         Mile,
+		Millimeter:        Inch,
+		MetersPerSecond:   MilesPerHour,
+		KilometersPerHour: MilesPerHour,
+		Pascal:            InchesOfMercury,
+		Hectopascal:       InchesOfMercury,
+	},
+	SI: {
+		Fahrenheit:      Celsius,
+		Foot:            Meter,
+		Mile:            Kilometer,
+		Inch:            Millimeter,
+		MilesPerHour:    KilometersPerHour,
+		Knot:            KilometersPerHour,
+		InchesOfMercury: Pascal,
+	},
+}
+
+// Units the quantity in the system's unit for its dimension. Unknown units are kept
+func (q Quantity) Units(system UnitSystem) Quantity {
+
+	from, err := q.Unit()
+
+	if err != nil {
+		return q
+	}
+
+	to, ok := systemUnits[system][from]
+
+	if !ok {
+		return q
+	}
+
+	converted, _ := q.Convert(to)
+
+	return converted
+}
+
+// unitsOr the quantity in the system's unit, or in the given unit of the system when it has one
+func (q Quantity) unitsOr(system UnitSystem, us Unit, si Unit) Quantity {
+
+	to := si
+
+	if system == US {
+		to = us
+	}
+
+	if converted, err := q.Convert(to); err == nil {
+		return converted
+	}
+
+	return q.Units(system)
+}
+
+// Units the feature's elevation in the unit system
+func (f Feature) Units(system UnitSystem) Feature {
+	f.Props.TheElevation = f.Props.TheElevation.Units(system)
+
+	return f
+}
+
+// Units the collection's features' elevations in the unit system
+func (c FeatureCollection) Units(system UnitSystem) FeatureCollection {
+	features := make([]Feature, len(c.Features))
+
+	for i, feature := range c.Features {
+		features[i] = feature.Units(system)
+	}
+
+	c.Features = features
+
+	return c
+}
+
+// Units the observation's measurements in the unit system. Visibility is in miles or meters
+func (o Observation) Units(system UnitSystem) Observation {
+
+	p := &o.Props
+
+	for _, q := range []*Quantity{
+		&p.Elevation, &p.Temperature, &p.Dewpoint, &p.WindDirection, &p.WindSpeed, &p.WindGust,
+		&p.BarometricPressure, &p.SeaLevelPressure, &p.MaxTemperatureLast24Hours, &p.MinTemperatureLast24Hours,
+		&p.PrecipitationLastHour, &p.RelativeHumidity, &p.WindChill, &p.HeatIndex,
+	} {
+		*q = q.Units(system)
+	}
+
+	p.Visibility = p.Visibility.unitsOr(system, Mile, Meter)
+
+	layers := make([]CloudLayer, len(p.CloudLayers))
+
+	for i, layer := range p.CloudLayers {
+		layer.Base = layer.Base.Units(system)
+		layers[i] = layer
+	}
+
+	if p.CloudLayers != nil {
+		p.CloudLayers = layers
+	}
+
+	return o
+}
+
+// windSpeedText a forecast wind speed such as "5 to 10 mph" or "15 km/h"
+var windSpeedText = regexp.MustCompile(`(\d+)(?: to (\d+))? (mph|km/h)`)
+
+// Units the forecast temperatures, wind speeds and elevation in the unit system
+func (f Forecast) Units(system UnitSystem) Forecast {
+
+	f.Props.Elevation = f.Props.Elevation.Units(system)
+
+	temperatureUnit, windUnit, windName := "C", KilometersPerHour, "km/h"
+
+	if system == US {
+		temperatureUnit, windUnit, windName = "F", MilesPerHour, "mph"
+	}
+
+	periods := make([]ForecastPeriod, len(f.Props.Periods))
+
+	for i, period := range f.Props.Periods {
+
+		if period.TemperatureUnit != temperatureUnit {
+			if from, err := ParseUnit("deg" + period.TemperatureUnit); err == nil {
+				t, _ := convert(period.Temperature, from, Unit("deg"+temperatureUnit))
+				period.Temperature = math.Round(t)
+				period.TemperatureUnit = temperatureUnit
+			}
+		}
+
+		period.WindSpeed = windSpeedText.ReplaceAllStringFunc(period.WindSpeed, func(text string) string {
+			match := windSpeedText.FindStringSubmatch(text)
+
+			from := MilesPerHour
+
+			if match[3] == "km/h" {
+				from = KilometersPerHour
+			}
+
+			speeds := make([]string, 0, 2)
+
+			for _, s := range match[1:3] {
+				if s == "" {
+					continue
+				}
+
+				v, _ := strconv.ParseFloat(s, 64)
+				v, _ = convert(v, from, windUnit)
+				speeds = append(speeds, strconv.Itoa(int(math.Round(v))))
+			}
+
+			return strings.Join(speeds, " to ") + " " + windName
+		})
+
+		period.ProbabilityOfPrecipitation = period.ProbabilityOfPrecipitation.Units(system)
+		periods[i] = period
+	}
+
+	if f.Props.Periods != nil {
+		f.Props.Periods = periods
+	}
+
+	f.Props.Units = string(system)
+
+	return f
+}
@@ -0,0 +1,201 @@
+package weather
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseUnit(t *testing.T) {
+
+	cases := map[string]Unit{
+		"wmoUnit:degC":           Celsius,
+		"unit:m":                 Meter,
+		"wmoUnit:km_h-1":         KilometersPerHour,
+		"wmoUnit:degree_(angle)": Degree,
+		"wmoUnit:percent":        Percent,
+		"unit:degF":              Fahrenheit,
+		"wmoUnit:Pa":             Pascal,
+		"[in_i'Hg]":              InchesOfMercury,
+	}
+
+	for code, expected := range cases {
+		if unit, err := ParseUnit(code); err != nil || unit != expected {
+			t.Errorf("Unit of %s not as expected. %s %+v\n", code, unit, err)
+		}
+	}
+
+	if _, err := ParseUnit("wmoUnit:furlong"); err == nil {
+		t.Errorf("Expected an unknown unit to fail\n")
+	}
+}
+
+func TestQuantityConvert(t *testing.T) {
+
+	cases := []struct {
+		value    float64
+		from     Unit
+		to       Unit
+		expected float64
+	}{
+		{100, Celsius, Fahrenheit, 212},
+		{-40, Fahrenheit, Celsius, -40},
+		{0, Celsius, Kelvin, 273.15},
+		{3.048, Meter, Foot, 10},
+		{16.09344, Kilometer, Mile, 10},
+		{22.224, KilometersPerHour, Knot, 12},
+		{10, MilesPerHour, KilometersPerHour, 16.09344},
+		{101420, Pascal, InchesOfMercury, 29.949},
+		{1013.25, Hectopascal, Pascal, 101325},
+	}
+
+	for _, c := range cases {
+		converted, err := NewQuantity(c.value, c.from).Convert(c.to)
+
+		if err != nil {
+			t.Fatalf("Converting %s to %s failed. %+v\n", c.from, c.to, err)
+		}
+
+		if converted.UnitCode != c.to.Code() || math.Abs(*converted.Value-c.expected) > 0.001 {
+			t.Errorf("%f %s in %s not as expected. %f %s\n", c.value, c.from, c.to, *converted.Value, converted.UnitCode)
+		}
+	}
+
+	if _, err := NewQuantity(10, Meter).Convert(Celsius); err == nil {
+		t.Errorf("Expected meters not to convert to degrees\n")
+	}
+
+	if _, ok := NewQuantity(50, Percent).In(Degree); ok {
+		t.Errorf("Expected percent not to convert to degrees\n")
+	}
+}
+
+func TestQuantityNull(t *testing.T) {
+
+	var gust Quantity
+
+	if err := json.Unmarshal([]byte(`{"unitCode": "wmoUnit:km_h-1", "value": null, "qualityControl": "Z"}`), &gust); err != nil {
+		t.Fatalf("Unmarshalling the quantity failed. %+v\n", err)
+	}
+
+	if _, ok := gust.In(MilesPerHour); ok {
+		t.Errorf("A null value has no value in any unit\n")
+	}
+
+	us := gust.Units(US)
+
+	if us.Value != nil || us.UnitCode != "unit:mi_h-1" || us.QualityControl != "Z" {
+		t.Errorf("A null value stays null in the new unit. %+v\n", us)
+	}
+
+	b, _ := json.Marshal(us)
+
+	if string(b) != `{"value":null,"unitCode":"unit:mi_h-1","qualityControl":"Z"}` {
+		t.Errorf("Null quantity JSON not as expected. %s\n", b)
+	}
+}
+
+func TestObservationUnits(t *testing.T) {
+
+	var observation Observation
+
+	if err := json.Unmarshal([]byte(latestObservation), &observation); err != nil {
+		t.Fatalf("Unmarshalling the observation failed. %+v\n", err)
+	}
+
+	us := observation.Units(US)
+
+	if v, _ := us.Props.Temperature.In(Fahrenheit); us.Props.Temperature.UnitCode != "wmoUnit:degF" || math.Abs(v-57.2) > 0.001 {
+		t.Errorf("Temperature not in Fahrenheit. %+v\n", us.Props.Temperature)
+	}
+
+	if v, _ := us.Props.Visibility.In(Mile); us.Props.Visibility.UnitCode != "unit:mi" || math.Abs(v-10) > 0.01 {
+		t.Errorf("Visibility not in miles. %+v\n", us.Props.Visibility)
+	}
+
+	if us.Props.WindDirection.UnitCode != "wmoUnit:degree_(angle)" || *us.Props.WindDirection.Value != 290 {
+		t.Errorf("Wind direction should not change. %+v\n", us.Props.WindDirection)
+	}
+
+	if us.Props.CloudLayers[0].Base.UnitCode != "unit:ft" {
+		t.Errorf("Cloud base not in feet. %+v\n", us.Props.CloudLayers[0].Base)
+	}
+
+	if *observation.Props.Temperature.Value != 14 || observation.Props.CloudLayers[0].Base.UnitCode != "wmoUnit:m" {
+		t.Errorf("Converting changed the original observation. %+v\n", observation.Props)
+	}
+
+	si := us.Units(SI)
+
+	if v, _ := si.Props.Temperature.In(Celsius); si.Props.Temperature.UnitCode != "wmoUnit:degC" || math.Abs(v-14) > 0.001 {
+		t.Errorf("Temperature not back in Celsius. %+v\n", si.Props.Temperature)
+	}
+}
+
+func TestForecastUnits(t *testing.T) {
+
+	forecast := Forecast{Props: ForecastProperties{Units: "us", Periods: []ForecastPeriod{
+		{Temperature: 71, TemperatureUnit: "F", WindSpeed: "5 to 10 mph"},
+		{Temperature: 50, TemperatureUnit: "F", WindSpeed: "15 mph"},
+	}}}
+
+	si := forecast.Units(SI)
+
+	if si.Props.Units != "si" {
+		t.Errorf("Forecast units not as expected. %s\n", si.Props.Units)
+	}
+
+	if period := si.Props.Periods[0]; period.Temperature != 22 || period.TemperatureUnit != "C" || period.WindSpeed != "8 to 16 km/h" {
+		t.Errorf("First period not in si. %+v\n", period)
+	}
+
+	if period := si.Props.Periods[1]; period.Temperature != 10 || period.WindSpeed != "24 km/h" {
+		t.Errorf("Second period not in si. %+v\n", period)
+	}
+
+	if forecast.Props.Periods[0].TemperatureUnit != "F" {
+		t.Errorf("Converting changed the original forecast. %+v\n", forecast.Props.Periods[0])
+	}
+}
+
+func TestUnitCodes(t *testing.T) {
+
+	// the units the US system converts to, only the Fahrenheit is a WMO code
+	expected := map[Unit]string{
+		Fahrenheit:      "wmoUnit:degF",
+		Foot:            "unit:ft",
+		Mile:            "unit:mi",
+		Inch:            "unit:in",
+		MilesPerHour:    "unit:mi_h-1",
+		InchesOfMercury: "unit:inHg",
+	}
+
+	for _, unit := range systemUnits[US] {
+		if code := unit.Code(); code != expected[unit] {
+			t.Errorf("Code of %s not as expected. %s\n", unit, code)
+		}
+
+		if parsed, err := ParseUnit(unit.Code()); err != nil || parsed != unit {
+			t.Errorf("Code of %s does not parse back. %s %+v\n", unit, parsed, err)
+		}
+	}
+
+	if Celsius.Code() != "wmoUnit:degC" || KilometersPerHour.Code() != "wmoUnit:km_h-1" || Hectopascal.Code() != "wmoUnit:hPa" {
+		t.Errorf("SI units should be WMO codes")
+	}
+}
+
+func TestParseUnitSystem(t *testing.T) {
+
+	for s, expected := range map[string]UnitSystem{"si": SI, "US": US} {
+		if system, err := ParseUnitSystem(s); err != nil || system != expected {
+			t.Errorf("Unit system %q not as expected. %s %+v\n", s, system, err)
+		}
+	}
+
+	for _, s := range []string{"", "imperial"} {
+		if _, err := ParseUnitSystem(s); err == nil {
+			t.Errorf("Expected %q to fail\n", s)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+// Package taf decodes terminal aerodrome forecasts into the groups of conditions forecast for each period
+package taf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EdSwArchitect/go-weather/weather/metar"
+)
+
+// ErrNoForecast the text has no TAF in it
+var ErrNoForecast = errors.New("No TAF found")
+
+// The kinds of forecast group
+const (
+	Base        = "BASE"  // the conditions from the start of the forecast until the first FM group
+	From        = "FM"    // the conditions from the time until the next FM group
+	Becoming    = "BECMG" // the conditions change gradually during the period
+	Temporary   = "TEMPO" // the conditions occur from time to time during the period
+	Probability = "PROB"  // the conditions have a chance of occurring during the period
+)
+
+// WindShear low level wind shear forecast at a height
+type WindShear struct {
+	Height    int    `json:"height"` // feet above the ground
+	Direction int    `json:"direction"`
+	Speed     int    `json:"speed"`
+	Unit      string `json:"unit"`
+}
+
+// Group the conditions forecast for a period. A FM group lasts until the next FM group
+type Group struct {
+	Kind        string    `json:"kind"`
+	Probability int       `json:"probability,omitempty"` // percent, for PROB groups and a PROB TEMPO
+	Temporary   bool      `json:"temporary,omitempty"`   // a PROB TEMPO group
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	metar.Conditions
+	WindShear            *WindShear `json:"windShear,omitempty"`
+	NoSignificantWeather bool       `json:"noSignificantWeather,omitempty"` // NSW, the forecast weather ends
+	Unparsed             []string   `json:"unparsed,omitempty"`
+}
+
+// Covers the time is within the group's period
+func (g Group) Covers(t time.Time) bool {
+	return !t.Before(g.From) && t.Before(g.To)
+}
+
+// Temperature a forecast maximum or minimum temperature, degrees Celsius
+type Temperature struct {
+	Value float64   `json:"value"`
+	At    time.Time `json:"at"`
+}
+
+// TAF a decoded terminal aerodrome forecast
+type TAF struct {
+	Raw            string       `json:"raw"`
+	Station        string       `json:"station"`
+	Issued         time.Time    `json:"issued"`
+	ValidFrom      time.Time    `json:"validFrom"`
+	ValidTo        time.Time    `json:"validTo"`
+	Amended        bool         `json:"amended,omitempty"`
+	Corrected      bool         `json:"corrected,omitempty"`
+	Cancelled      bool         `json:"cancelled,omitempty"`
+	Groups         []Group      `json:"groups"`
+	MaxTemperature *Temperature `json:"maxTemperature,omitempty"`
+	MinTemperature *Temperature `json:"minTemperature,omitempty"`
+	Remarks        string       `json:"remarks,omitempty"`
+}
+
+// At the prevailing group at the time, then the BECMG, TEMPO and PROB groups covering it
+func (t TAF) At(when time.Time) []Group {
+
+	groups := make([]Group, 0)
+
+	for _, group := range t.Groups {
+		if (group.Kind == Base || group.Kind == From) && group.Covers(when) {
+			groups = append(groups, group)
+		}
+	}
+
+	for _, group := range t.Groups {
+		if group.Kind != Base && group.Kind != From && group.Covers(when) {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+var (
+	stationGroup     = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	issuedGroup      = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	periodGroup      = regexp.MustCompile(`^(\d{2})(\d{2})/(\d{2})(\d{2})$`)
+	fromGroup        = regexp.MustCompile(`^FM(\d{2})(\d{2})(\d{2})$`)
+	probabilityGroup = regexp.MustCompile(`^PROB(\d{2})$`)
+	windShearGroup   = regexp.MustCompile(`^WS(\d{3})/(\d{3})(\d{2,3})(KT|MPS|KMH)$`)
+	temperatureGroup = regexp.MustCompile(`^T([XN])(M?\d{2})/(\d{2})(\d{2})Z$`)
+)
+
+// dayTime a day of the month and time, resolved against a reference time
+type dayTime struct {
+	day, hour, minute int
+}
+
+// in the time on the day in the month of ref, or the month after when the day has already passed. Hour 24 is
+// midnight at the end of the day
+func (d dayTime) in(ref time.Time) time.Time {
+
+	t := time.Date(ref.Year(), ref.Month(), d.day, d.hour, d.minute, 0, 0, time.UTC)
+
+	if d.day < ref.Day() {
+		t = time.Date(ref.Year(), ref.Month()+1, d.day, d.hour, d.minute, 0, 0, time.UTC)
+	}
+
+	return t
+}
+
+func number(s string) int {
+	i, _ := strconv.Atoi(s)
+
+	return i
+}
+
+// Parse decode the TAF in the text, such as the text of a weather service TAF product with its header lines. ref
+// is a time shortly after the TAF was issued, such as the product issuance time, so the days resolve to dates
+func Parse(text string, ref time.Time) (TAF, error) {
+
+	fields := strings.Fields(text)
+
+	// the product header comes before the TAF keyword
+	for i, field := range fields {
+		if field == "TAF" {
+			fields = fields[i+1:]
+			break
+		}
+	}
+
+	// the forecast ends at the =, or the $$ that ends the product
+	for i, field := range fields {
+		if field == "$$" {
+			fields = fields[:i]
+			break
+		}
+
+		if strings.HasSuffix(field, "=") {
+			fields = append(fields[:i:i], strings.TrimSuffix(field, "="))
+			break
+		}
+	}
+
+	forecast := TAF{Raw: strings.Join(append([]string{"TAF"}, fields...), " ")}
+
+	for len(fields) > 0 && (fields[0] == "AMD" || fields[0] == "COR") {
+		forecast.Amended = forecast.Amended || fields[0] == "AMD"
+		forecast.Corrected = forecast.Corrected || fields[0] == "COR"
+		fields = fields[1:]
+	}
+
+	if len(fields) < 3 || !stationGroup.MatchString(fields[0]) {
+		return forecast, ErrNoForecast
+	}
+
+	forecast.Station = fields[0]
+
+	match := issuedGroup.FindStringSubmatch(fields[1])
+
+	if match == nil {
+		return forecast, fmt.Errorf("Invalid issue time %q", fields[1])
+	}
+
+	// the issue time is at or before ref, in its month or the month before
+	ref = ref.UTC()
+	forecast.Issued = time.Date(ref.Year(), ref.Month(), number(match[1]), number(match[2]), number(match[3]), 0, 0, time.UTC)
+
+	if forecast.Issued.After(ref.Add(24 * time.Hour)) {
+		forecast.Issued = forecast.Issued.AddDate(0, -1, 0)
+	}
+
+	from, to, ok := period(fields[2], forecast.Issued)
+
+	if !ok {
+		return forecast, fmt.Errorf("Invalid valid period %q", fields[2])
+	}
+
+	forecast.ValidFrom, forecast.ValidTo = from, to
+
+	for i, field := range fields {
+		if field == "RMK" {
+			forecast.Remarks = strings.Join(fields[i+1:], " ")
+			fields = fields[:i]
+			break
+		}
+	}
+
+	group := Group{Kind: Base, From: from, To: to}
+	var conditions []string
+
+	// finish the current group and start the next
+	finish := func(next Group) {
+		forecast.addGroup(group, conditions)
+		group, conditions = next, nil
+	}
+
+	for i := 3; i < len(fields); i++ {
+		field := fields[i]
+
+		if match := fromGroup.FindStringSubmatch(field); match != nil {
+			finish(Group{Kind: From, From: dayTime{number(match[1]), number(match[2]), number(match[3])}.in(forecast.Issued), To: to})
+			continue
+		}
+
+		if (field == Becoming || field == Temporary) && i+1 < len(fields) {
+			if from, to, ok := period(fields[i+1], forecast.Issued); ok {
+				// a PROB30 TEMPO is one group
+				if group.Kind == Probability && field == Temporary && len(conditions) == 0 {
+					group.Temporary = true
+					group.From, group.To = from, to
+				} else {
+					finish(Group{Kind: field, From: from, To: to})
+				}
+
+				i++
+				continue
+			}
+		}
+
+		if match := probabilityGroup.FindStringSubmatch(field); match != nil && i+1 < len(fields) {
+			next := Group{Kind: Probability, Probability: number(match[1])}
+
+			if from, to, ok := period(fields[i+1], forecast.Issued); ok {
+				next.From, next.To = from, to
+				i++
+			}
+
+			finish(next)
+			continue
+		}
+
+		if field == "CNL" {
+			forecast.Cancelled = true
+			continue
+		}
+
+		conditions = append(conditions, field)
+	}
+
+	forecast.addGroup(group, conditions)
+
+	// a FM group lasts until the next
+	for i := range forecast.Groups {
+		if forecast.Groups[i].Kind != Base && forecast.Groups[i].Kind != From {
+			continue
+		}
+
+		for _, next := range forecast.Groups[i+1:] {
+			if next.Kind == From {
+				forecast.Groups[i].To = next.From
+				break
+			}
+		}
+	}
+
+	return forecast, nil
+}
+
+// addGroup decode the conditions of the group and add it to the forecast. The forecast temperatures
+// are kept on the forecast
+func (t *TAF) addGroup(group Group, conditions []string) {
+
+	group.Unparsed = group.Decode(conditions, func(field string) bool {
+
+		if field == "NSW" {
+			group.NoSignificantWeather = true
+			return true
+		}
+
+		if match := windShearGroup.FindStringSubmatch(field); match != nil {
+			group.WindShear = &WindShear{
+				Height:    number(match[1]) * 100,
+				Direction: number(match[2]),
+				Speed:     number(match[3]),
+				Unit:      match[4],
+			}
+
+			return true
+		}
+
+		if match := temperatureGroup.FindStringSubmatch(field); match != nil {
+			value := float64(number(strings.TrimPrefix(match[2], "M")))
+
+			if strings.HasPrefix(match[2], "M") {
+				value = -value
+			}
+
+			temperature := &Temperature{Value: value, At: dayTime{number(match[3]), number(match[4]), 0}.in(t.Issued)}
+
+			if match[1] == "X" {
+				t.MaxTemperature = temperature
+			} else {
+				t.MinTemperature = temperature
+			}
+
+			return true
+		}
+
+		return false
+	})
+
+	// a cancelled TAF has no base conditions
+	if group.Kind == Base && len(conditions) == 0 {
+		return
+	}
+
+	t.Groups = append(t.Groups, group)
+}
+
+// period a DDHH/DDHH period after the issue time
+func period(field string, issued time.Time) (time.Time, time.Time, bool) {
+
+	match := periodGroup.FindStringSubmatch(field)
+
+	if match == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from := dayTime{number(match[1]), number(match[2]), 0}.in(issued)
+	to := dayTime{number(match[3]), number(match[4]), 0}.in(issued)
+
+	return from, to, true
+}
@@ -0,0 +1,133 @@
+package taf
+
+import (
+	"testing"
+	"time"
+)
+
+// product the text of a weather service TAF product, with its header lines
+const product = `
+000
+FTUS46 KMTR 181120
+TAFSFO
+
+TAF
+KSFO 181120Z 1812/1918 29012KT P6SM FEW008 SCT200
+     TEMPO 1812/1815 BKN008
+     FM181800 30015G22KT P6SM SKC WS020/24045KT
+     FM190300 28010KT 3SM BR OVC005
+     PROB30 1906/1910 1/2SM FG VV002
+     BECMG 1914/1916 31008KT=
+
+$$
+`
+
+func TestParse(t *testing.T) {
+
+	ref := time.Date(2026, 10, 18, 11, 25, 0, 0, time.UTC)
+
+	forecast, err := Parse(product, ref)
+
+	if err != nil {
+		t.Fatalf("Parsing failed. %+v\n", err)
+	}
+
+	hour := func(day int, h int) time.Time {
+		return time.Date(2026, 10, day, h, 0, 0, 0, time.UTC)
+	}
+
+	if forecast.Station != "KSFO" || !forecast.Issued.Equal(time.Date(2026, 10, 18, 11, 20, 0, 0, time.UTC)) ||
+		!forecast.ValidFrom.Equal(hour(18, 12)) || !forecast.ValidTo.Equal(hour(19, 18)) {
+		t.Errorf("Header not as expected. %+v\n", forecast)
+	}
+
+	expected := []struct {
+		kind string
+		from time.Time
+		to   time.Time
+	}{
+		{Base, hour(18, 12), hour(18, 18)},
+		{Temporary, hour(18, 12), hour(18, 15)},
+		{From, hour(18, 18), hour(19, 3)},
+		{From, hour(19, 3), hour(19, 18)},
+		{Probability, hour(19, 6), hour(19, 10)},
+		{Becoming, hour(19, 14), hour(19, 16)},
+	}
+
+	if len(forecast.Groups) != len(expected) {
+		t.Fatalf("Expected %d groups. %+v\n", len(expected), forecast.Groups)
+	}
+
+	for i, e := range expected {
+		group := forecast.Groups[i]
+
+		if group.Kind != e.kind || !group.From.Equal(e.from) || !group.To.Equal(e.to) {
+			t.Errorf("Group %d not as expected. %s %s %s\n", i, group.Kind, group.From, group.To)
+		}
+
+		if len(group.Unparsed) > 0 {
+			t.Errorf("Group %d has groups not decoded. %v\n", i, group.Unparsed)
+		}
+	}
+
+	base := forecast.Groups[0]
+
+	if base.Wind == nil || *base.Wind.Direction != 290 || base.Wind.Speed != 12 || !base.Visibility.MoreThan || len(base.Clouds) != 2 {
+		t.Errorf("Base conditions not as expected. %+v\n", base.Conditions)
+	}
+
+	if shear := forecast.Groups[2].WindShear; shear == nil || shear.Height != 2000 || shear.Direction != 240 || shear.Speed != 45 {
+		t.Errorf("Wind shear not as expected. %+v\n", shear)
+	}
+
+	if prob := forecast.Groups[4]; prob.Probability != 30 || prob.Visibility.Distance != 0.5 || prob.Weather[0].Code != "FG" {
+		t.Errorf("PROB30 group not as expected. %+v\n", prob)
+	}
+
+	at := forecast.At(hour(19, 7))
+
+	if len(at) != 2 || at[0].Kind != From || at[1].Kind != Probability {
+		t.Errorf("Groups in effect not as expected. %+v\n", at)
+	}
+}
+
+func TestParseInternational(t *testing.T) {
+
+	forecast, err := Parse("TAF AMD EGLL 302258Z 3100/0106 24010KT 9999 SCT030 TX15/3114Z TN08/0105Z "+
+		"PROB30 TEMPO 3112/3118 7000 -SHRA BECMG 0100/0103 VRB03KT NSW", time.Date(2026, 10, 30, 23, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatalf("Parsing failed. %+v\n", err)
+	}
+
+	if !forecast.Amended || !forecast.ValidTo.Equal(time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected an amended forecast into the next month. %+v\n", forecast)
+	}
+
+	if forecast.MaxTemperature == nil || forecast.MaxTemperature.Value != 15 ||
+		!forecast.MaxTemperature.At.Equal(time.Date(2026, 10, 31, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("Maximum temperature not as expected. %+v\n", forecast.MaxTemperature)
+	}
+
+	if len(forecast.Groups) != 3 {
+		t.Fatalf("Expected 3 groups. %+v\n", forecast.Groups)
+	}
+
+	if prob := forecast.Groups[1]; prob.Kind != Probability || !prob.Temporary || prob.Probability != 30 ||
+		!prob.From.Equal(time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("PROB30 TEMPO group not as expected. %+v\n", prob)
+	}
+
+	if becoming := forecast.Groups[2]; !becoming.NoSignificantWeather || !becoming.Wind.Variable {
+		t.Errorf("BECMG group not as expected. %+v\n", becoming)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+
+	for _, text := range []string{"", "000 FTUS46 KMTR 181120", "TAF KSFO 1811Z 1812/1918", "TAF KSFO 181120Z 18/19"} {
+		if _, err := Parse(text, time.Now()); err == nil {
+			t.Errorf("Expected %q not to parse\n", text)
+		}
+	}
+}
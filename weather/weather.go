@@ -1,11 +1,13 @@
 package weather
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-
-	"github.com/go-resty/resty/v2"
+	"strconv"
+	"strings"
 )
 
 // Geometry type
@@ -14,23 +16,17 @@ type Geometry struct {
 	Coordinates []float64 `json:"coordinates"`
 }
 
-// Elevation type
-type Elevation struct {
-	Value    float64 `json:"value"`
-	UnitCode string  `json:"unitCode"`
-}
-
 // Properties type
 type Properties struct {
-	ID              string    `json:"@id"`
-	Type            string    `json:"@type"`
-	TheElevation    Elevation `json:"elevation"`
-	StationID       string    `json:"stationIdentifier"`
-	Name            string    `json:"name"`
-	TimeZone        string    `json:"timeZone"`
-	Forecast        string    `json:"forecast"`
-	County          string    `json:"county"`
-	FireWeatherZone string    `json:"fireWeatherZone"`
+	ID              string   `json:"@id"`
+	Type            string   `json:"@type"`
+	TheElevation    Quantity `json:"elevation"`
+	StationID       string   `json:"stationIdentifier"`
+	Name            string   `json:"name"`
+	TimeZone        string   `json:"timeZone"`
+	Forecast        string   `json:"forecast"`
+	County          string   `json:"county"`
+	FireWeatherZone string   `json:"fireWeatherZone"`
 }
 
 // Weather type
@@ -50,18 +46,67 @@ type Feature struct {
 	Props Properties `json:"properties"`
 }
 
+// StationID the station identifier, from the properties or the end of the feature ID
+func (f Feature) StationID() string {
+	if f.Props.StationID != "" {
+		return f.Props.StationID
+	}
+
+	return f.ID[strings.LastIndex(f.ID, "/")+1:]
+}
+
+// CountyZone the county zone ID, e.g. CAC081, at the end of the county URL
+func (f Feature) CountyZone() string {
+	return f.Props.County[strings.LastIndex(f.Props.County, "/")+1:]
+}
+
+// State the two letter state from the county zone, or the forecast zone when there is no county
+func (f Feature) State() string {
+	zone := f.CountyZone()
+
+	if zone == "" {
+		zone = f.Props.Forecast[strings.LastIndex(f.Props.Forecast, "/")+1:]
+	}
+
+	if len(zone) < 2 {
+		return ""
+	}
+
+	return strings.ToUpper(zone[:2])
+}
+
 // Stations The stations
 type Stations struct {
 	ObservationStations []string
 }
 
-// FeatureCollection the feature collection
+// FeatureCollection the feature collection. It is written as RFC 7946 GeoJSON, with Next as a foreign
+// member, the cursor of the next page when the collection is one page of the stations
 type FeatureCollection struct {
 	Type                string    `json:"type"`
 	Features            []Feature `json:"features"`
 	ObservationStations Stations  `json:"observationStations"`
+	Next                string    `json:"next,omitempty"`
+}
+
+// Pagination the link to the next page of a collection
+type Pagination struct {
+	Next string `json:"next"`
 }
 
+// StationPage one page of the stations list
+type StationPage struct {
+	Features            []Feature  `json:"features"`
+	ObservationStations []string   `json:"observationStations"`
+	Pagination          Pagination `json:"pagination"`
+}
+
+// ErrNotFound the weather service has no such station
+var ErrNotFound = errors.New("Not found")
+
+// ErrStopPaging returned by a page callback to stop walking pages without an error
+var ErrStopPaging = errors.New("stop paging")
+
 // FB a type....
 type FB struct {
 	Type     string    `json:"type"`
@@ -101,127 +146,216 @@ func ParseFeatureCollection(jayson string) (FeatureCollection, error) {
 
 // GetObservationStations ....
 func GetObservationStations() (Stations, error) {
-	client := resty.New()
+	return DefaultClient.GetObservationStations()
+}
 
-	resp, err := client.R().Get("https://api.weather.gov/stations")
+// GetObservationStationsContext ....
+func GetObservationStationsContext(ctx context.Context) (Stations, error) {
+	return DefaultClient.GetObservationStationsContext(ctx)
+}
 
-	var stations Stations
+// GetStations get the stations
+func GetStations() (string, error) {
+	return DefaultClient.GetStations()
+}
 
-	if err != nil {
+// GetStationsContext get the stations
+func GetStationsContext(ctx context.Context) (string, error) {
+	return DefaultClient.GetStationsContext(ctx)
+}
 
-		log.Printf("Getting the stations list failed: %+v", err)
+// GetFeatures get the weather features
+func GetFeatures() ([]Feature, error) {
+	return DefaultClient.GetFeatures()
+}
 
-		return stations, err
-	}
+// GetFeaturesContext get the weather features
+func GetFeaturesContext(ctx context.Context) ([]Feature, error) {
+	return DefaultClient.GetFeaturesContext(ctx)
+}
 
-	fmt.Printf("Status code of call: %d\n", resp.StatusCode())
+// EachStationPage call fn with each page of the stations list
+func EachStationPage(fn func(page StationPage) error) error {
+	return DefaultClient.EachStationPage(fn)
+}
 
-	if resp.StatusCode() != 200 {
+// EachStationPageContext call fn with each page of the stations list
+func EachStationPageContext(ctx context.Context, fn func(page StationPage) error) error {
+	return DefaultClient.EachStationPageContext(ctx, fn)
+}
 
-		return stations, fmt.Errorf("Status code returned: %d", resp.StatusCode())
-	}
+// GetFeature for the station ID
+func GetFeature(stationID string) (Feature, error) {
+	return DefaultClient.GetFeature(stationID)
+}
 
-	// fmt.Printf("%s\n", resp)
+// GetFeatureContext for the station ID
+func GetFeatureContext(ctx context.Context, stationID string) (Feature, error) {
+	return DefaultClient.GetFeatureContext(ctx, stationID)
+}
 
-	err = json.Unmarshal([]byte(resp.String()), &stations)
+// GetObservationStations wraps GetObservationStationsContext using context.Background
+func (c *Client) GetObservationStations() (Stations, error) {
+	return c.GetObservationStationsContext(context.Background())
+}
+
+// GetObservationStationsContext all of the stations, following the pagination links
+func (c *Client) GetObservationStationsContext(ctx context.Context) (Stations, error) {
+
+	var stations Stations
+
+	err := c.EachStationPageContext(ctx, func(page StationPage) error {
+		stations.ObservationStations = append(stations.ObservationStations, page.ObservationStations...)
+		return nil
+	})
 
 	if err != nil {
-		var s Stations
-		fmt.Printf("Error parsing json\n")
-		return s, err
-	}
+		log.Printf("Getting the stations list failed: %+v", err)
 
-	// fmt.Printf("Size of observations: %d\n", len(stations.ObservationStations))
+		return Stations{}, err
+	}
 
 	return stations, nil
 }
 
-// GetStations get the stations
-func GetStations() (string, error) {
-	// https://api.weather.gov/stations
+// EachStationPage wraps EachStationPageContext using context.Background
+func (c *Client) EachStationPage(fn func(page StationPage) error) error {
+	return c.EachStationPageContext(context.Background(), fn)
+}
 
-	client := resty.New()
+// EachStationPageContext call fn with each page of the stations list, following the pagination links
+// until there are no more stations or the configured maximum pages are read. fn returns
+// ErrStopPaging to stop early
+func (c *Client) EachStationPageContext(ctx context.Context, fn func(page StationPage) error) error {
 
-	resp, err := client.R().Get("https://api.weather.gov/stations")
+	next := "/stations"
+	params := map[string]string{"limit": strconv.Itoa(c.pageSize)}
 
-	if err != nil {
-		return "", err
-	}
+	for pages := 0; next != ""; pages++ {
 
-	fmt.Printf("Status code of call: %d\n", resp.StatusCode())
+		if pages == c.maxPages {
+			log.Printf("Stopped listing stations after %d pages", pages)
+			return nil
+		}
 
-	if resp.StatusCode() != 200 {
-		return "", fmt.Errorf("Status code returned: %d", resp.StatusCode())
-	}
+		resp, err := c.rest.R().SetContext(ctx).SetQueryParams(params).Get(next)
 
-	// fmt.Printf("%s\n", resp)
+		if err != nil {
+			return err
+		}
 
-	// var w Feature
+		if resp.StatusCode() != 200 {
+			return fmt.Errorf("Status code returned: %d", resp.StatusCode())
+		}
 
-	// w, err = ParseWeather(resp.String())
+		var page StationPage
 
-	// if err != nil {
-	// 	fmt.Printf("Error parsing json\n")
-	// }
+		err = json.Unmarshal(resp.Body(), &page)
 
-	return resp.String(), nil
+		if err != nil {
+			return err
+		}
+
+		if len(page.Features) == 0 && len(page.ObservationStations) == 0 {
+			return nil
+		}
+
+		err = fn(page)
+
+		if err == ErrStopPaging {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// the next link already carries the cursor and limit
+		if page.Pagination.Next == next {
+			return nil
+		}
+
+		next = page.Pagination.Next
+		params = nil
+	}
+
+	return nil
 }
 
-// GetFeatures get the weather features
-func GetFeatures() ([]Feature, error) {
-	// https://api.weather.gov/stations
+// GetStations wraps GetStationsContext using context.Background
+func (c *Client) GetStations() (string, error) {
+	return c.GetStationsContext(context.Background())
+}
 
-	client := resty.New()
+// GetStationsContext get the stations
+func (c *Client) GetStationsContext(ctx context.Context) (string, error) {
 
-	resp, err := client.R().Get("https://api.weather.gov/stations")
+	resp, err := c.rest.R().SetContext(ctx).Get("/stations")
 
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	// fmt.Printf("Status code of call: %d\n", resp.StatusCode())
+	fmt.Printf("Status code of call: %d\n", resp.StatusCode())
 
 	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+		return "", fmt.Errorf("Status code returned: %d", resp.StatusCode())
 	}
 
-	// fmt.Printf("%s\n", resp)
+	return resp.String(), nil
+}
+
+// GetFeatures wraps GetFeaturesContext using context.Background
+func (c *Client) GetFeatures() ([]Feature, error) {
+	return c.GetFeaturesContext(context.Background())
+}
+
+// GetFeaturesContext get the weather features, following the pagination links
+func (c *Client) GetFeaturesContext(ctx context.Context) ([]Feature, error) {
 
-	var features FB
+	var features []Feature
 
-	err = json.Unmarshal([]byte(resp.String()), &features)
+	err := c.EachStationPageContext(ctx, func(page StationPage) error {
+		features = append(features, page.Features...)
+		return nil
+	})
 
 	if err != nil {
-		log.Printf("Failed unmarshalling into features %s", err)
+		log.Printf("Failed getting features %s", err)
 		return nil, err
 	}
 
-	return features.Features, nil
+	return features, nil
 }
 
-// GetFeature for the station ID
-func GetFeature(stationID string) (Feature, error) {
-	// https://api.weather.gov/stations
+// GetFeature wraps GetFeatureContext using context.Background
+func (c *Client) GetFeature(stationID string) (Feature, error) {
+	return c.GetFeatureContext(context.Background(), stationID)
+}
 
-	client := resty.New()
+// GetFeatureContext for the station ID. A station the weather service does not know is ErrNotFound
+func (c *Client) GetFeatureContext(ctx context.Context, stationID string) (Feature, error) {
 
-	resp, err := client.R().Get(fmt.Sprintf("https://api.weather.gov/stations/%s", stationID))
+	resp, err := c.rest.R().
+		SetContext(ctx).
+		SetPathParams(map[string]string{"stationId": stationID}).
+		Get("/stations/{stationId}")
 
 	if err != nil {
 		return Feature{}, err
 	}
 
-	// fmt.Printf("Status code of call: %d\n", resp.StatusCode())
+	if resp.StatusCode() == 404 {
+		return Feature{}, fmt.Errorf("Station %s %w", stationID, ErrNotFound)
+	}
 
 	if resp.StatusCode() != 200 {
 		return Feature{}, fmt.Errorf("Status code returned: %d", resp.StatusCode())
 	}
 
-	// fmt.Printf("%s\n", resp)
-
 	var feature Feature
 
-	err = json.Unmarshal([]byte(resp.String()), &feature)
+	err = json.Unmarshal(resp.Body(), &feature)
 
 	if err != nil {
 		log.Printf("Failed unmarshalling into features %s", err)
@@ -1,7 +1,10 @@
 package weather
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -87,3 +90,60 @@ func TestNoWeatherFeature(t *testing.T) {
 	fmt.Printf("The feature: %+v\n", feature)
 
 }
+
+func TestFeatureNotFound(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stations/GOOBER" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	if _, err := client.GetFeature("GOOBER"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("A missing station should be not found. %+v\n", err)
+	}
+
+	if _, err := client.GetFeature("KBOI"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("A failing weather service is not a missing station. %+v\n", err)
+	}
+}
+
+func TestFeatureZones(t *testing.T) {
+
+	feature := Feature{
+		ID: "https://api.weather.gov/stations/KSFO",
+		Props: Properties{
+			County:   "https://api.weather.gov/zones/county/CAC081",
+			Forecast: "https://api.weather.gov/zones/forecast/CAZ508",
+		},
+	}
+
+	if feature.StationID() != "KSFO" {
+		t.Errorf("Station ID not as expected. %s\n", feature.StationID())
+	}
+
+	if feature.CountyZone() != "CAC081" {
+		t.Errorf("County zone not as expected. %s\n", feature.CountyZone())
+	}
+
+	if feature.State() != "CA" {
+		t.Errorf("State not as expected. %s\n", feature.State())
+	}
+
+	feature.Props.County = ""
+
+	if feature.State() != "CA" {
+		t.Errorf("State from the forecast zone not as expected. %s\n", feature.State())
+	}
+
+	if (Feature{}).State() != "" {
+		t.Errorf("A feature without zones has no state")
+	}
+}
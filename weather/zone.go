@@ -0,0 +1,186 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// zoneReads the most zones of an alert read at once
+const zoneReads = 8
+
+// cachedZone a zone outline and when it was read
+type cachedZone struct {
+	shape  *Shape
+	readAt time.Time
+}
+
+// Zone a forecast, county or fire zone. Geo is the zone's outline
+type Zone struct {
+	ID  string `json:"id"`
+	Geo *Shape `json:"geometry"`
+}
+
+// GetZone wraps GetZoneContext using context.Background
+func GetZone(zoneURL string) (Zone, error) {
+	return DefaultClient.GetZone(zoneURL)
+}
+
+// GetZoneContext the zone at the URL, as listed in an alert's affected zones
+func GetZoneContext(ctx context.Context, zoneURL string) (Zone, error) {
+	return DefaultClient.GetZoneContext(ctx, zoneURL)
+}
+
+// ResolveAlertArea wraps ResolveAlertAreaContext using context.Background
+func ResolveAlertArea(alert Alert) (Alert, error) {
+	return DefaultClient.ResolveAlertArea(alert)
+}
+
+// ResolveAlertAreaContext the alert with the outlines of its affected zones when it has no geometry
+func ResolveAlertAreaContext(ctx context.Context, alert Alert) (Alert, error) {
+	return DefaultClient.ResolveAlertAreaContext(ctx, alert)
+}
+
+// GetZone wraps GetZoneContext using context.Background
+func (c *Client) GetZone(zoneURL string) (Zone, error) {
+	return c.GetZoneContext(context.Background(), zoneURL)
+}
+
+// GetZoneContext the zone at the URL, as listed in an alert's affected zones
+func (c *Client) GetZoneContext(ctx context.Context, zoneURL string) (Zone, error) {
+
+	var zone Zone
+
+	resp, err := c.rest.R().
+		SetContext(ctx).
+		Get(zoneURL)
+
+	if err != nil {
+		return zone, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return zone, fmt.Errorf("Status code returned: %d", resp.StatusCode())
+	}
+
+	err = json.Unmarshal(resp.Body(), &zone)
+
+	return zone, err
+}
+
+// ResolveAlertArea wraps ResolveAlertAreaContext using context.Background
+func (c *Client) ResolveAlertArea(alert Alert) (Alert, error) {
+	return c.ResolveAlertAreaContext(context.Background(), alert)
+}
+
+// ResolveAlertAreaContext the alert with the outlines of its affected zones when it has no geometry, so
+// a point in any of the zones is in the alert. The zones are read zoneReads at a time. Zones that can
+// not be read are left out and the first error is returned with the area of the zones that were read
+func (c *Client) ResolveAlertAreaContext(ctx context.Context, alert Alert) (Alert, error) {
+
+	if alert.Geo != nil || len(alert.Props.AffectedZones) == 0 {
+		return alert, nil
+	}
+
+	shapes := make([]*Shape, len(alert.Props.AffectedZones))
+	errs := make([]error, len(alert.Props.AffectedZones))
+
+	reads := make(chan struct{}, zoneReads)
+
+	var wg sync.WaitGroup
+
+	for i, zoneURL := range alert.Props.AffectedZones {
+
+		wg.Add(1)
+
+		go func(i int, zoneURL string) {
+			defer wg.Done()
+
+			reads <- struct{}{}
+			defer func() { <-reads }()
+
+			shapes[i], errs[i] = c.zoneShape(ctx, zoneURL)
+		}(i, zoneURL)
+	}
+
+	wg.Wait()
+
+	var polygons [][][][]float64
+	var firstErr error
+
+	for i, shape := range shapes {
+
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Unable to get zone %s. %s", alert.Props.AffectedZones[i], errs[i])
+			}
+
+			continue
+		}
+
+		if shape != nil {
+			polygons = append(polygons, shape.Polygons...)
+		}
+	}
+
+	if len(polygons) > 0 {
+		alert.Geo = &Shape{Type: "MultiPolygon", Polygons: polygons}
+	}
+
+	return alert, firstErr
+}
+
+// zoneShape the zone's outline, read from the weather service when it is not kept or older than the
+// zone TTL. A zone without an outline is nil
+func (c *Client) zoneShape(ctx context.Context, zoneURL string) (*Shape, error) {
+
+	c.zonesMu.Lock()
+	cached, found := c.zones[zoneURL]
+	c.zonesMu.Unlock()
+
+	if found && time.Since(cached.readAt) < c.zoneTTL {
+		return cached.shape, nil
+	}
+
+	zone, err := c.GetZoneContext(ctx, zoneURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.zonesMu.Lock()
+	defer c.zonesMu.Unlock()
+
+	if _, found := c.zones[zoneURL]; !found && len(c.zones) >= c.maxZones {
+		c.dropZones()
+	}
+
+	c.zones[zoneURL] = cachedZone{shape: zone.Geo, readAt: time.Now()}
+
+	return zone.Geo, nil
+}
+
+// dropZones make room for a zone. The expired zones are dropped, or the oldest when none are. The
+// caller holds zonesMu
+func (c *Client) dropZones() {
+
+	oldest := ""
+
+	for zoneURL, cached := range c.zones {
+
+		if time.Since(cached.readAt) >= c.zoneTTL {
+			delete(c.zones, zoneURL)
+			continue
+		}
+
+		if oldest == "" || cached.readAt.Before(c.zones[oldest].readAt) {
+			oldest = zoneURL
+		}
+	}
+
+	if len(c.zones) >= c.maxZones && oldest != "" {
+		delete(c.zones, oldest)
+	}
+}
@@ -0,0 +1,107 @@
+package weather
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolveAlertArea(t *testing.T) {
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		switch r.URL.Path {
+		case "/zones/forecast/CAZ006":
+			fmt.Fprint(w, `{"id": "https://api.weather.gov/zones/forecast/CAZ006", "type": "Feature",
+				"geometry": {"type": "Polygon", "coordinates": [[[-123, 37], [-122, 37], [-122, 38], [-123, 38], [-123, 37]]]},
+				"properties": {"id": "CAZ006", "name": "San Francisco"}}`)
+		case "/zones/forecast/CAZ508":
+			fmt.Fprint(w, `{"id": "https://api.weather.gov/zones/forecast/CAZ508", "type": "Feature",
+				"geometry": {"type": "MultiPolygon", "coordinates": [[[[-122, 37], [-121, 37], [-121, 38], [-122, 38], [-122, 37]]]]},
+				"properties": {"id": "CAZ508", "name": "San Francisco Bay Shoreline"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	wind := Alert{ID: "wind"}
+	wind.Props.AffectedZones = []string{server.URL + "/zones/forecast/CAZ006", server.URL + "/zones/forecast/CAZ508"}
+
+	resolved, err := client.ResolveAlertArea(wind)
+
+	if err != nil || resolved.Geo == nil || len(resolved.Geo.Polygons) != 2 {
+		t.Fatalf("Expected the outlines of both zones. %+v %+v\n", resolved.Geo, err)
+	}
+
+	if !resolved.Contains(37.5, -122.5) || !resolved.Contains(37.5, -121.5) || resolved.Contains(37.5, -120.5) {
+		t.Errorf("Points should match the affected zones. %+v\n", resolved.Geo)
+	}
+
+	// the zones are read once
+	client.ResolveAlertArea(wind)
+
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected the zones to be read once. %d\n", calls)
+	}
+
+	missing := wind
+	missing.Props.AffectedZones = append(missing.Props.AffectedZones, server.URL+"/zones/forecast/CAZ999")
+
+	resolved, err = client.ResolveAlertArea(missing)
+
+	if err == nil || resolved.Geo == nil || len(resolved.Geo.Polygons) != 2 {
+		t.Errorf("A missing zone should be reported and the rest kept. %+v %+v\n", resolved.Geo, err)
+	}
+}
+
+func TestZoneCache(t *testing.T) {
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"id": "zone", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}`)
+	}))
+
+	defer server.Close()
+
+	alert := func(zone string) Alert {
+		a := Alert{ID: zone}
+		a.Props.AffectedZones = []string{server.URL + "/zones/forecast/" + zone}
+		return a
+	}
+
+	// only one zone is kept, reading another drops it
+	client := NewClient(ClientConfig{BaseURL: server.URL, MaxZones: 1})
+
+	client.ResolveAlertArea(alert("A"))
+	client.ResolveAlertArea(alert("A"))
+	client.ResolveAlertArea(alert("B"))
+	client.ResolveAlertArea(alert("A"))
+
+	if atomic.LoadInt32(&calls) != 3 || len(client.zones) != 1 {
+		t.Errorf("Expected the oldest zone dropped. %d %d\n", calls, len(client.zones))
+	}
+
+	// an expired zone is read again
+	atomic.StoreInt32(&calls, 0)
+	client = NewClient(ClientConfig{BaseURL: server.URL, ZoneTTL: time.Nanosecond})
+
+	client.ResolveAlertArea(alert("A"))
+	time.Sleep(time.Millisecond)
+	client.ResolveAlertArea(alert("A"))
+
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected the expired zone read again. %d\n", calls)
+	}
+}